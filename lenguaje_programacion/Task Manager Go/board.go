@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+const (
+	boardGap          = 2
+	boardMinColumn    = 12
+	defaultBoardWidth = 80
+)
+
+// boardColumns son las columnas del tablero, de izquierda a derecha.
+var boardColumns = []Status{TODO, INPROGRESS, DONE}
+
+// boardCursor señala una tarjeta: columna y fila dentro de ella.
+type boardCursor struct {
+	col, row int
+}
+
+// boardLayout reparte las tareas por columna; en cada una van primero las de
+// más prioridad y, a igual prioridad, por ID.
+func boardLayout(tasks []Task) [][]Task {
+	cols := make([][]Task, len(boardColumns))
+	for _, t := range tasks {
+		for i, status := range boardColumns {
+			if t.Status == status {
+				cols[i] = append(cols[i], t)
+			}
+		}
+	}
+	for _, col := range cols {
+		sortTasks(col, "id")
+		sortTasks(col, "priority")
+	}
+	return cols
+}
+
+// terminalWidth devuelve el ancho de f, o $COLUMNS y 80 si no es una terminal.
+func terminalWidth(f *os.File) int {
+	if w, _, err := term.GetSize(f.Fd()); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return defaultBoardWidth
+}
+
+// renderBoard dibuja las columnas una al lado de otra en width caracteres.
+// rows limita las tarjetas visibles por columna (0 = todas) y desplaza la
+// columna seleccionada para que cur quede a la vista; cur nil no marca nada.
+func renderBoard(cols [][]Task, width, rows int, cur *boardCursor, now time.Time) string {
+	colWidth := max((width-boardGap*(len(cols)-1))/len(cols), boardMinColumn)
+	rendered := make([]string, len(cols))
+	for i, col := range cols {
+		status := boardColumns[i]
+		header := lipgloss.NewStyle().Bold(true).Width(colWidth).
+			Foreground(lipgloss.Color("0")).Background(lipgloss.Color(statusColors[status])).
+			Render(ansi.Truncate(fmt.Sprintf(" %s (%d)", status, len(col)), colWidth, "…"))
+		lines := []string{header}
+		first, last := 0, len(col)
+		if rows > 0 && len(col) > rows {
+			if cur != nil && cur.col == i {
+				first = max(cur.row-rows+1, 0)
+			}
+			last = first + rows
+		}
+		for j := first; j < last; j++ {
+			t := col[j]
+			card := ansi.Truncate(fmt.Sprintf("[%d] %s%s%s", t.ID, t.Title, priorityLabel(t.Priority), dueLabel(t, now)), colWidth, "…")
+			style := lipgloss.NewStyle().Width(colWidth).Foreground(lipgloss.Color(statusColors[status]))
+			if cur != nil && cur.col == i && cur.row == j {
+				style = style.Reverse(true).Bold(true)
+			}
+			lines = append(lines, style.Render(card))
+		}
+		if len(col) == 0 {
+			lines = append(lines, lipgloss.NewStyle().Width(colWidth).Faint(true).Render("(vacía)"))
+		}
+		rendered[i] = strings.Join(lines, "\n")
+	}
+	gap := strings.Repeat(" ", boardGap)
+	parts := make([]string, 0, 2*len(rendered)-1)
+	for i, col := range rendered {
+		if i > 0 {
+			parts = append(parts, gap)
+		}
+		parts = append(parts, col)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+const boardHelp = "←/→ columna · ↑/↓ tarjeta · shift+←/→ o </> mover tarjeta · r recargar · q salir"
+
+// boardModel es el modo interactivo de board. Mover una tarjeta usa
+// startTask y setStatus, igual que start y done.
+type boardModel struct {
+	cols   [][]Task
+	cur    boardCursor
+	msg    string
+	err    bool
+	width  int
+	height int
+}
+
+func newBoardModel(width int) (boardModel, error) {
+	m := boardModel{width: width, height: 24}
+	return m, m.reload(0)
+}
+
+// reload vuelve a cargar el tablero; si follow no es 0, el cursor pasa a esa
+// tarea, p. ej. la tarjeta recién movida.
+func (m *boardModel) reload(follow int) error {
+	var tasks []Task
+	err := withStore(func(s Store) error {
+		var err error
+		if tasks, err = s.Load(); err != nil {
+			return storageError("cargando tareas", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	m.cols = boardLayout(tasks)
+	for i, col := range m.cols {
+		for j, t := range col {
+			if t.ID == follow {
+				m.cur = boardCursor{i, j}
+				return nil
+			}
+		}
+	}
+	m.clamp()
+	return nil
+}
+
+func (m *boardModel) clamp() {
+	m.cur.col = min(max(m.cur.col, 0), len(m.cols)-1)
+	m.cur.row = min(max(m.cur.row, 0), max(len(m.cols[m.cur.col])-1, 0))
+}
+
+func (m boardModel) selected() (Task, bool) {
+	col := m.cols[m.cur.col]
+	if m.cur.row >= len(col) {
+		return Task{}, false
+	}
+	return col[m.cur.row], true
+}
+
+// move pasa la tarjeta seleccionada a la columna de al lado (dir = ±1).
+func (m *boardModel) move(dir int) {
+	t, ok := m.selected()
+	target := m.cur.col + dir
+	if !ok || target < 0 || target >= len(boardColumns) {
+		return
+	}
+	status := boardColumns[target]
+	var out bytes.Buffer
+	err := withStore(func(s Store) error {
+		if status == INPROGRESS {
+			return startTask(&out, s, t.ID, false)
+		}
+		return setStatus(&out, s, t.ID, status)
+	})
+	if err != nil {
+		m.msg, m.err = err.Error(), true
+	} else {
+		m.msg, m.err = strings.TrimSpace(strings.SplitN(out.String(), "\n", 2)[0]), false
+	}
+	if err := m.reload(t.ID); err != nil {
+		m.msg, m.err = err.Error(), true
+	}
+}
+
+func (m boardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m boardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "left", "h":
+			m.cur.col--
+		case "right", "l":
+			m.cur.col++
+		case "up", "k":
+			m.cur.row--
+		case "down", "j":
+			m.cur.row++
+		case "shift+left", "<", "H":
+			m.move(-1)
+		case "shift+right", ">", "L":
+			m.move(1)
+		case "r":
+			m.msg, m.err = "", false
+			if err := m.reload(0); err != nil {
+				m.msg, m.err = err.Error(), true
+			}
+		}
+		m.clamp()
+	}
+	return m, nil
+}
+
+func (m boardModel) View() string {
+	// Cabeceras, barra de estado y ayuda.
+	board := renderBoard(m.cols, m.width, max(m.height-3, 1), &m.cur, timeNow())
+	status := m.msg
+	if m.err {
+		status = uiErrorMsg.Render("Error: " + m.msg)
+	}
+	return board + "\n" + status + "\n" + uiFaint.MaxWidth(m.width).Render(boardHelp)
+}
+
+var cmdBoard = &cobra.Command{
+	Use:   "board",
+	Short: "Mostrar las tareas como tablero kanban",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		width, _ := cmd.Flags().GetInt("width")
+		if width <= 0 {
+			width = terminalWidth(os.Stdout)
+		}
+		if interactive {
+			if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+				return validationErrorf("taskcli board --interactive necesita una terminal interactiva")
+			}
+			m, err := newBoardModel(width)
+			if err != nil {
+				return err
+			}
+			_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+			return err
+		}
+		return withStore(func(s Store) error {
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			return writeBoard(os.Stdout, tasks, width, timeNow())
+		})
+	},
+}
+
+func writeBoard(w io.Writer, tasks []Task, width int, now time.Time) error {
+	_, err := fmt.Fprintln(w, renderBoard(boardLayout(tasks), width, 0, nil, now))
+	return err
+}
+
+func init() {
+	cmdBoard.Flags().BoolP("interactive", "i", false, "Modo interactivo para mover tarjetas entre columnas")
+	cmdBoard.Flags().Int("width", 0, "Ancho del tablero (por defecto, el de la terminal)")
+}
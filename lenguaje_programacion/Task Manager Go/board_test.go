@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestRenderBoard(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	long := NewTask(1, "Un título bastante largo que no cabe en la columna", "")
+	urgent := NewTask(2, "Hotfix", "")
+	urgent.Priority = URGENT
+	doing := NewTask(3, "Migrar", "")
+	doing.Status = INPROGRESS
+
+	out := renderBoard(boardLayout([]Task{long, urgent, doing}), 60, 0, nil, now)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Líneas = %d, esperadas 3 (cabecera y dos tarjetas):\n%s", len(lines), out)
+	}
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w > 60 {
+			t.Errorf("Línea de %d caracteres, máximo 60: %q", w, line)
+		}
+	}
+	for _, want := range []string{"TODO (2)", "IN_PROGRESS (1)", "DONE (0)", "(vacía)", "[3] Migrar"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Falta %q en:\n%s", want, out)
+		}
+	}
+	// La urgente va primero y el título largo se recorta con "…".
+	if !strings.HasPrefix(lines[1], "[2] Hotfix") || !strings.Contains(lines[2], "[1] Un título bas…") {
+		t.Errorf("Columna TODO inesperada:\n%s", out)
+	}
+}
+
+func TestBoardMoveCards(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	blocked := NewTask(2, "Desplegar", "")
+	blocked.BlockedBy = []int{1}
+	saveTasks([]Task{NewTask(1, "Preparar", ""), blocked})
+
+	m, err := newBoardModel(90)
+	if err != nil {
+		t.Fatalf("newBoardModel: %v", err)
+	}
+	send := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			next, _ := m.Update(msg)
+			m = next.(boardModel)
+		}
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	send(tea.KeyMsg{Type: tea.KeyShiftRight})
+	tasks, _ := loadTasks()
+	if tasks[0].Status != INPROGRESS || !tasks[0].timerRunning() {
+		t.Errorf("Tarea 1 = %v, esperada IN_PROGRESS con temporizador", tasks[0].Status)
+	}
+	if m.cur != (boardCursor{1, 0}) {
+		t.Errorf("Cursor = %+v, debería seguir a la tarjeta movida", m.cur)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyLeft}, key(">"))
+	if !m.err || !strings.Contains(m.msg, "bloqueada por 1") {
+		t.Errorf("Mover tarea bloqueada = %q, esperado error", m.msg)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRight}, key(">"))
+	if tasks, _ = loadTasks(); tasks[0].Status != DONE || !tasks[0].UpdatedAt.After(tasks[0].CreatedAt) {
+		t.Errorf("Tarea 1 = %+v, esperada DONE", tasks[0])
+	}
+	send(key(">"))
+	if tasks, _ = loadTasks(); tasks[0].Status != DONE {
+		t.Error("Más allá de DONE no hay columna")
+	}
+	send(key("<"), key("<"))
+	if tasks, _ = loadTasks(); tasks[0].Status != TODO {
+		t.Errorf("Status = %v, esperado TODO tras volver dos columnas", tasks[0].Status)
+	}
+	if !strings.Contains(m.View(), "TODO (2)") {
+		t.Errorf("Vista sin actualizar:\n%s", m.View())
+	}
+
+	// Devolver a TODO una tarjeta en curso detiene su temporizador.
+	send(key(">"))
+	if tasks, _ = loadTasks(); !tasks[0].timerRunning() {
+		t.Fatalf("Tarea 1 = %+v, esperado temporizador en marcha", tasks[0])
+	}
+	send(key("<"))
+	if tasks, _ = loadTasks(); tasks[0].Status != TODO || tasks[0].timerRunning() || tasks[0].timerPaused() {
+		t.Errorf("Tarea 1 = %+v, esperada TODO sin temporizador", tasks[0])
+	}
+	if !strings.Contains(m.msg, "marcada como TODO") || m.err {
+		t.Errorf("Mensaje = %q", m.msg)
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func captureOutput(f func()) string {
@@ -28,6 +31,8 @@ func setupTestEnv(t *testing.T) func() {
 	originalHome := os.Getenv("HOME")
 	tmpDir := t.TempDir()
 	os.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(homeEnvVar, "")
 
 	path, _ := tasksFilePath()
 	if _, err := os.Stat(path); err == nil {
@@ -37,8 +42,34 @@ func setupTestEnv(t *testing.T) func() {
 	cmdAdd.Flags().Set("title", "")
 	cmdAdd.Flags().Set("desc", "")
 	cmdList.Flags().Set("state", "all")
+	cmdList.Flags().Set("sort", "id")
+	cmdList.Flags().Set("priority", "")
+	cmdList.Flags().Set("overdue", "false")
+	cmdList.Flags().Set("due-before", "")
+	cmdList.Flags().Set("project", "")
+	cmdList.Flags().Set("tree", "false")
+	cmdList.Flags().Set("ready", "false")
+	cmdStart.Flags().Set("force", "false")
+	cmdGraph.Flags().Set("format", "dot")
+	cmdGraph.Flags().Set("group-by", "project")
+	cmdGraph.Flags().Set("state", "all")
+	cmdLink.Flags().Set("kind", defaultLinkKind)
+	cmdLog.Flags().Set("date", "")
+	cmdReport.Flags().Set("from", "")
+	cmdReport.Flags().Set("to", "")
+	cmdReport.Flags().Set("group-by", "day")
+	for _, cmd := range []*cobra.Command{cmdList, cmdGraph} {
+		cmd.Flags().Set("where", "")
+		cmd.Flags().Set("query", "")
+	}
+	cmdRemove.Flags().Set("recursive", "false")
+	for _, name := range []string{"tag", "any-tag"} {
+		cmdList.Flags().Lookup(name).Value.(pflag.SliceValue).Replace(nil)
+	}
 	cmdEdit.Flags().Set("title", "")
 	cmdEdit.Flags().Set("desc", "")
+	cmdAdd.Flags().Set("repeat", "")
+	cmdEdit.Flags().Set("repeat", "")
 
 	return func() {
 		os.Setenv("HOME", originalHome)
@@ -52,7 +83,7 @@ func TestCmdAddSuccess(t *testing.T) {
 	tempCmd := &cobra.Command{
 		Use:   "add",
 		Short: "Agregar una nueva tarea",
-		Run:   cmdAdd.Run,
+		RunE:  cmdAdd.RunE,
 	}
 	tempCmd.Flags().StringP("title", "t", "", "Título de la tarea")
 	tempCmd.Flags().StringP("desc", "d", "", "Descripción")
@@ -86,21 +117,27 @@ func TestCmdAddWithoutTitle(t *testing.T) {
 	cleanup := setupTestEnv(t)
 	defer cleanup()
 
+	var err error
 	output := captureOutput(func() {
 		tempCmd := &cobra.Command{
-			Use:   "add",
-			Short: "Agregar una nueva tarea",
-			Run:   cmdAdd.Run,
+			Use:           "add",
+			Short:         "Agregar una nueva tarea",
+			RunE:          cmdAdd.RunE,
+			SilenceErrors: true,
+			SilenceUsage:  true,
 		}
 		tempCmd.Flags().StringP("title", "t", "", "Título de la tarea")
 		tempCmd.Flags().StringP("desc", "d", "", "Descripción")
 
 		tempCmd.SetArgs([]string{"--desc", "Solo descripción"})
-		tempCmd.Execute()
+		err = tempCmd.Execute()
 	})
 
-	if output == "" {
-		t.Error("Se esperaba mensaje de error")
+	if exitCode(err) != exitValidation {
+		t.Errorf("Error = %v, se esperaba un error de validación", err)
+	}
+	if output != "" {
+		t.Errorf("stdout debe quedar limpio, obtenido: %v", output)
 	}
 
 	tasks, _ := loadTasks()
@@ -117,7 +154,7 @@ func TestCmdListEmpty(t *testing.T) {
 		tempCmd := &cobra.Command{
 			Use:   "list",
 			Short: "Listar tareas",
-			Run:   cmdList.Run,
+			RunE:  cmdList.RunE,
 		}
 		tempCmd.Flags().StringP("state", "s", "all", "Filtrar por estado")
 
@@ -145,7 +182,7 @@ func TestCmdListWithTasks(t *testing.T) {
 
 	cmdList.Flags().Set("state", "all")
 	output := captureOutput(func() {
-		cmdList.Run(cmdList, []string{})
+		cmdList.RunE(cmdList, []string{})
 	})
 
 	if output == "" {
@@ -154,7 +191,7 @@ func TestCmdListWithTasks(t *testing.T) {
 
 	cmdList.Flags().Set("state", "todo")
 	output = captureOutput(func() {
-		cmdList.Run(cmdList, []string{})
+		cmdList.RunE(cmdList, []string{})
 	})
 
 	if output == "" {
@@ -170,7 +207,7 @@ func TestCmdViewExistingTask(t *testing.T) {
 	saveTasks([]Task{task})
 
 	output := captureOutput(func() {
-		cmdView.Run(cmdView, []string{"1"})
+		cmdView.RunE(cmdView, []string{"1"})
 	})
 
 	if output == "" {
@@ -182,11 +219,16 @@ func TestCmdViewNonExistingTask(t *testing.T) {
 	cleanup := setupTestEnv(t)
 	defer cleanup()
 
+	var err error
 	output := captureOutput(func() {
-		cmdView.Run(cmdView, []string{"999"})
+		err = cmdView.RunE(cmdView, []string{"999"})
 	})
 
-	if output != "Tarea 999 no encontrada\n" {
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != 999 {
+		t.Errorf("Error = %v, esperado NotFoundError para 999", err)
+	}
+	if output != "" {
 		t.Errorf("Output inesperado: %v", output)
 	}
 }
@@ -198,7 +240,7 @@ func TestCmdStart(t *testing.T) {
 	task := NewTask(1, "Tarea", "Desc")
 	saveTasks([]Task{task})
 
-	cmdStart.Run(cmdStart, []string{"1"})
+	cmdStart.RunE(cmdStart, []string{"1"})
 
 	tasks, _ := loadTasks()
 	if tasks[0].Status != INPROGRESS {
@@ -218,12 +260,15 @@ func TestCmdDone(t *testing.T) {
 	task.Status = INPROGRESS
 	saveTasks([]Task{task})
 
-	cmdDone.Run(cmdDone, []string{"1"})
+	cmdDone.RunE(cmdDone, []string{"1"})
 
 	tasks, _ := loadTasks()
 	if tasks[0].Status != DONE {
 		t.Errorf("Status = %v, esperado DONE", tasks[0].Status)
 	}
+	if tasks[0].CompletedAt == nil || !tasks[0].CompletedAt.Equal(tasks[0].UpdatedAt) {
+		t.Errorf("CompletedAt = %v, esperado %v", tasks[0].CompletedAt, tasks[0].UpdatedAt)
+	}
 }
 
 func TestCmdEditTitle(t *testing.T) {
@@ -238,7 +283,7 @@ func TestCmdEditTitle(t *testing.T) {
 			Use:   "edit <id>",
 			Short: "Editar título y/o descripción de una tarea",
 			Args:  cobra.ExactArgs(1),
-			Run:   cmdEdit.Run,
+			RunE:  cmdEdit.RunE,
 		}
 		tempCmd.Flags().StringP("title", "t", "", "Nuevo título")
 		tempCmd.Flags().StringP("desc", "d", "", "Nueva descripción")
@@ -273,7 +318,7 @@ func TestCmdEditDescription(t *testing.T) {
 			Use:   "edit <id>",
 			Short: "Editar título y/o descripción de una tarea",
 			Args:  cobra.ExactArgs(1),
-			Run:   cmdEdit.Run,
+			RunE:  cmdEdit.RunE,
 		}
 		tempCmd.Flags().StringP("title", "t", "", "Nuevo título")
 		tempCmd.Flags().StringP("desc", "d", "", "Nueva descripción")
@@ -307,7 +352,7 @@ func TestCmdEditWithoutFlags(t *testing.T) {
 	cmdEdit.Flags().Set("desc", "")
 
 	output := captureOutput(func() {
-		cmdEdit.Run(cmdEdit, []string{"1"})
+		cmdEdit.RunE(cmdEdit, []string{"1"})
 	})
 
 	if output == "" {
@@ -324,9 +369,11 @@ func TestCmdRemove(t *testing.T) {
 		NewTask(2, "Tarea 2", ""),
 		NewTask(3, "Tarea 3", ""),
 	}
+	tasks[1].BlockedBy = []int{1}
+	tasks[1].Links = []Link{{Kind: defaultLinkKind, ID: 1}, {Kind: defaultLinkKind, ID: 3}}
 	saveTasks(tasks)
 
-	cmdRemove.Run(cmdRemove, []string{"1"})
+	cmdRemove.RunE(cmdRemove, []string{"1"})
 
 	loadedTasks, _ := loadTasks()
 	if len(loadedTasks) != 2 {
@@ -338,11 +385,22 @@ func TestCmdRemove(t *testing.T) {
 			t.Error("La tarea 1 no debería existir")
 		}
 	}
+	if got := loadedTasks[0]; len(got.BlockedBy) != 0 || len(got.Links) != 1 || got.Links[0].ID != 3 {
+		t.Errorf("Tarea 2 = %+v, no debería conservar referencias a la 1", got)
+	}
+
+	// Un ID eliminado no se reutiliza, aunque fuera el más alto.
+	cmdRemove.RunE(cmdRemove, []string{"3"})
+	s, _ := openStore()
+	defer s.Close()
+	if added, err := s.Insert(NewTask(0, "Nueva", "")); err != nil || added.ID != 4 {
+		t.Errorf("Insert = %d, %v; esperado el ID 4", added.ID, err)
+	}
 }
 
 func TestCmdVersion(t *testing.T) {
 	output := captureOutput(func() {
-		cmdVersion.Run(cmdVersion, []string{})
+		cmdVersion.RunE(cmdVersion, []string{})
 	})
 
 	if output == "" {
@@ -359,3 +417,639 @@ func TestTimeNow(t *testing.T) {
 		t.Error("timeNow() debería retornar tiempo actual")
 	}
 }
+
+func TestCmdListSortAndFilterByPriority(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	tasks := []Task{
+		NewTask(1, "Baja", ""),
+		NewTask(2, "Urgente", ""),
+		NewTask(3, "Normal", ""),
+		NewTask(4, "Alta", ""),
+	}
+	tasks[0].Priority = LOW
+	tasks[1].Priority = URGENT
+	tasks[3].Priority = HIGH
+	saveTasks(tasks)
+
+	cmdList.Flags().Set("sort", "priority")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	expected := "[2] Urgente (TODO) [URGENT]\n[4] Alta (TODO) [HIGH]\n[3] Normal (TODO)\n[1] Baja (TODO) [LOW]\n"
+	if output != expected {
+		t.Errorf("Output = %q, esperado %q", output, expected)
+	}
+
+	cmdList.Flags().Set("sort", "id")
+	cmdList.Flags().Set("priority", "high")
+	output = captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if output != "[4] Alta (TODO) [HIGH]\n" {
+		t.Errorf("Output filtrado = %q", output)
+	}
+}
+
+func TestCmdListOverdueAndDueBefore(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	now := time.Now()
+	yesterday := endOfDay(addDays(now, -1))
+	nextWeek := endOfDay(addDays(now, 7))
+	tasks := []Task{
+		NewTask(1, "Vencida", ""),
+		NewTask(2, "Futura", ""),
+		NewTask(3, "Sin fecha", ""),
+		NewTask(4, "Vencida pero hecha", ""),
+	}
+	tasks[0].Due = &yesterday
+	tasks[1].Due = &nextWeek
+	tasks[3].Due = &yesterday
+	tasks[3].Status = DONE
+	saveTasks(tasks)
+
+	cmdList.Flags().Set("overdue", "true")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if !strings.HasPrefix(output, "[1] Vencida (TODO) (venció ") || strings.Count(output, "\n") != 1 {
+		t.Errorf("Output --overdue = %q", output)
+	}
+
+	cmdList.Flags().Set("overdue", "false")
+	cmdList.Flags().Set("due-before", "in 10 days")
+	output = captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if strings.Count(output, "\n") != 3 || strings.Contains(output, "Sin fecha") {
+		t.Errorf("Output --due-before = %q", output)
+	}
+}
+
+func TestCmdAgenda(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	now := time.Now()
+	yesterday := endOfDay(addDays(now, -1))
+	today := endOfDay(now)
+	later := endOfDay(addDays(now, 30))
+	tasks := []Task{
+		NewTask(1, "Ayer", ""),
+		NewTask(2, "Hoy", ""),
+		NewTask(3, "Luego", ""),
+		NewTask(4, "Sin fecha", ""),
+	}
+	tasks[0].Due = &yesterday
+	tasks[1].Due = &today
+	tasks[2].Due = &later
+	saveTasks(tasks)
+
+	output := captureOutput(func() {
+		cmdAgenda.RunE(cmdAgenda, []string{})
+	})
+	for _, want := range []string{"Vencidas:", "Hoy:", "Más adelante:", "[1] Ayer", "[3] Luego"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Falta %q en la agenda:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Sin fecha") {
+		t.Errorf("La agenda no debe incluir tareas sin fecha:\n%s", output)
+	}
+}
+
+func TestCmdListTagFiltersAndTags(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	tasks := []Task{
+		NewTask(1, "API", ""),
+		NewTask(2, "Web", ""),
+		NewTask(3, "Ambas", ""),
+	}
+	tasks[0].Tags = []string{"backend", "api"}
+	tasks[1].Tags = []string{"frontend"}
+	tasks[2].Tags = []string{"backend", "frontend"}
+	saveTasks(tasks)
+
+	cmdList.Flags().Set("tag", "backend")
+	cmdList.Flags().Set("tag", "frontend")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if output != "[3] Ambas (TODO) #backend #frontend\n" {
+		t.Errorf("Output --tag (AND) = %q", output)
+	}
+
+	cmdList.Flags().Lookup("tag").Value.(pflag.SliceValue).Replace(nil)
+	cmdList.Flags().Set("any-tag", "api")
+	cmdList.Flags().Set("any-tag", "frontend")
+	output = captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if strings.Count(output, "\n") != 3 {
+		t.Errorf("Output --any-tag (OR) = %q", output)
+	}
+
+	output = captureOutput(func() {
+		cmdTags.RunE(cmdTags, []string{})
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "backend") || !strings.HasSuffix(lines[0], " 2") {
+		t.Errorf("Output tags = %q", output)
+	}
+}
+
+func TestCmdProjects(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	tasks := []Task{
+		NewTask(1, "API", ""),
+		NewTask(2, "DB", ""),
+		NewTask(3, "Web", ""),
+		NewTask(4, "Suelta", ""),
+	}
+	tasks[0].Project = "backend.api"
+	tasks[0].Status = DONE
+	tasks[1].Project = "backend.db"
+	tasks[1].Status = INPROGRESS
+	tasks[2].Project = "frontend"
+	saveTasks(tasks)
+
+	output := captureOutput(func() {
+		cmdProjects.RunE(cmdProjects, []string{})
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Se esperaban 6 líneas:\n%s", output)
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "backend 0 1 1" {
+		t.Errorf("Fila backend = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "  api") || !strings.HasPrefix(lines[5], "(sin proyecto)") {
+		t.Errorf("Output inesperado:\n%s", output)
+	}
+
+	cmdList.Flags().Set("project", "backend")
+	output = captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if strings.Count(output, "\n") != 2 || !strings.Contains(output, "@backend.db") {
+		t.Errorf("Output list --project = %q", output)
+	}
+}
+
+func TestCmdRemoveWithSubtasks(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(subtaskTestTasks())
+
+	var err error
+	captureOutput(func() {
+		err = cmdRemove.RunE(cmdRemove, []string{"1"})
+	})
+	if exitCode(err) != exitValidation {
+		t.Errorf("rm de una tarea con subtareas = %v, esperado error de validación", err)
+	}
+	if tasks, _ := loadTasks(); len(tasks) != 5 {
+		t.Fatalf("No se debería haber eliminado nada, quedan %d", len(tasks))
+	}
+
+	cmdRemove.Flags().Set("recursive", "true")
+	captureOutput(func() {
+		err = cmdRemove.RunE(cmdRemove, []string{"1"})
+	})
+	if err != nil {
+		t.Fatalf("rm --recursive: %v", err)
+	}
+	tasks, _ := loadTasks()
+	if len(tasks) != 1 || tasks[0].ID != 5 {
+		t.Errorf("Tareas restantes = %+v, esperada solo la 5", tasks)
+	}
+
+	// Todo el árbol se elimina con una sola escritura: una única copia, con
+	// las cinco tareas de antes.
+	path, _ := tasksFilePath()
+	data, err := os.ReadFile(backupPath(path, 1))
+	if err != nil {
+		t.Fatalf("Copia 1: %v", err)
+	}
+	if backup, _ := decodeTasks(data); len(backup) != 5 {
+		t.Errorf("La copia 1 tiene %d tareas, esperadas 5", len(backup))
+	}
+	if _, err := os.Stat(backupPath(path, 2)); !os.IsNotExist(err) {
+		t.Error("rm --recursive no debería rotar más de una copia")
+	}
+}
+
+func TestCmdListTreeAndViewProgress(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(subtaskTestTasks())
+
+	cmdList.Flags().Set("tree", "true")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if !strings.Contains(output, "\n  [2] Hija (DONE)\n    [3] Nieta (TODO)\n") {
+		t.Errorf("Output --tree = %q", output)
+	}
+
+	output = captureOutput(func() {
+		cmdView.RunE(cmdView, []string{"1"})
+	})
+	if !strings.Contains(output, "Progreso: 2/3 subtareas completadas (66%)") {
+		t.Errorf("view no muestra el progreso:\n%s", output)
+	}
+}
+
+func TestCmdStartBlocked(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(depsTestTasks())
+
+	var err error
+	captureOutput(func() {
+		err = cmdStart.RunE(cmdStart, []string{"2"})
+	})
+	if exitCode(err) != exitValidation || !strings.Contains(err.Error(), "bloqueada por 1") {
+		t.Errorf("start de una tarea bloqueada = %v, esperado error de validación", err)
+	}
+	if task, _ := loadTasks(); task[1].Status != TODO {
+		t.Errorf("Status = %v, esperado TODO", task[1].Status)
+	}
+
+	cmdStart.Flags().Set("force", "true")
+	captureOutput(func() {
+		err = cmdStart.RunE(cmdStart, []string{"2"})
+	})
+	if err != nil {
+		t.Fatalf("start --force: %v", err)
+	}
+	if task, _ := loadTasks(); task[1].Status != INPROGRESS {
+		t.Errorf("Status tras --force = %v, esperado IN_PROGRESS", task[1].Status)
+	}
+}
+
+func TestCmdListReady(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	tasks := depsTestTasks()
+	tasks[0].Status = DONE
+	saveTasks(tasks)
+
+	cmdList.Flags().Set("ready", "true")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if !strings.Contains(output, "[2] Implementación") || !strings.Contains(output, "[4] Suelta") {
+		t.Errorf("Output list --ready = %q, esperadas 2 y 4", output)
+	}
+	if strings.Contains(output, "[1]") || strings.Contains(output, "[3]") {
+		t.Errorf("Output list --ready = %q, no debería incluir 1 ni 3", output)
+	}
+}
+
+func TestCmdDependsAndUndepend(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(depsTestTasks())
+
+	var err error
+	captureOutput(func() {
+		err = cmdDepends.RunE(cmdDepends, []string{"1", "3"})
+	})
+	if exitCode(err) != exitValidation || !strings.Contains(err.Error(), "ciclo") {
+		t.Errorf("depends con ciclo = %v, esperado error de validación", err)
+	}
+
+	captureOutput(func() {
+		err = cmdDepends.RunE(cmdDepends, []string{"3", "4", "1"})
+	})
+	if err != nil {
+		t.Fatalf("depends: %v", err)
+	}
+	tasks, _ := loadTasks()
+	if joinIDs(tasks[2].BlockedBy, ",") != "1,2,4" {
+		t.Errorf("BlockedBy = %v, esperado [1 2 4]", tasks[2].BlockedBy)
+	}
+
+	output := captureOutput(func() {
+		cmdView.RunE(cmdView, []string{"3"})
+	})
+	if !strings.Contains(output, "Depende de: 1, 2, 4 (pendientes: 1, 2, 4)") {
+		t.Errorf("Output view = %q", output)
+	}
+
+	captureOutput(func() {
+		err = cmdUndepend.RunE(cmdUndepend, []string{"3", "2"})
+	})
+	if err != nil {
+		t.Fatalf("undepend: %v", err)
+	}
+	tasks, _ = loadTasks()
+	if joinIDs(tasks[2].BlockedBy, ",") != "1,4" {
+		t.Errorf("BlockedBy tras undepend = %v, esperado [1 4]", tasks[2].BlockedBy)
+	}
+}
+
+func TestCmdLinkAndGraph(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(graphTestTasks()[:3])
+
+	var err error
+	captureOutput(func() {
+		err = cmdLink.RunE(cmdLink, []string{"3", "1"})
+	})
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	captureOutput(func() {
+		err = cmdLink.RunE(cmdLink, []string{"3", "7"})
+	})
+	if exitCode(err) != exitNotFound {
+		t.Errorf("link a tarea inexistente = %v, esperado no encontrada", err)
+	}
+
+	cmdGraph.Flags().Set("format", "mermaid")
+	output := captureOutput(func() {
+		err = cmdGraph.RunE(cmdGraph, []string{})
+	})
+	if err != nil {
+		t.Fatalf("graph: %v", err)
+	}
+	if !strings.Contains(output, "t3 -.->|related| t1") || !strings.Contains(output, "t2 ==>|bloquea| t3") {
+		t.Errorf("Output graph = %q", output)
+	}
+
+	cmdGraph.Flags().Set("state", "todo")
+	output = captureOutput(func() {
+		cmdGraph.RunE(cmdGraph, []string{})
+	})
+	if strings.Contains(output, "t1[") || strings.Contains(output, "-->") || !strings.Contains(output, "t3[") {
+		t.Errorf("Output graph --state todo = %q, esperada solo la tarea 3 sin aristas", output)
+	}
+
+	captureOutput(func() {
+		err = cmdUnlink.RunE(cmdUnlink, []string{"3", "1"})
+	})
+	if tasks, _ := loadTasks(); err != nil || len(tasks[2].Links) != 0 {
+		t.Errorf("unlink = %v, enlaces %v", err, tasks[2].Links)
+	}
+}
+
+func TestCmdListWhereAndSavedQuery(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(queryTestTasks())
+
+	cmdList.Flags().Set("where", "status != DONE and tag:backend")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+	if !strings.Contains(output, "[3] Migrar base de datos") || strings.Contains(output, "[1]") || strings.Contains(output, "[2]") {
+		t.Errorf("Output list --where = %q, esperada solo la tarea 3", output)
+	}
+
+	cmdList.Flags().Set("where", "status = ")
+	err := cmdList.RunE(cmdList, []string{})
+	if exitCode(err) != exitValidation || !strings.Contains(err.Error(), "se esperaba un valor") {
+		t.Errorf("list --where inválido = %v, esperado error de validación", err)
+	}
+	cmdList.Flags().Set("where", "")
+
+	captureOutput(func() {
+		err = cmdQuerySave.RunE(cmdQuerySave, []string{"urgentes", "priority", ">=", "high", "and", "status != done"})
+	})
+	if err != nil {
+		t.Fatalf("query save: %v", err)
+	}
+	if err := cmdQuerySave.RunE(cmdQuerySave, []string{"rota", "priority >"}); exitCode(err) != exitValidation {
+		t.Errorf("query save inválida = %v, esperado error de validación", err)
+	}
+
+	output = captureOutput(func() {
+		cmdQueryList.RunE(cmdQueryList, []string{})
+	})
+	if output != "urgentes: priority >= high and status != done\n" {
+		t.Errorf("Output query list = %q", output)
+	}
+
+	cmdList.Flags().Set("query", "urgentes")
+	output = captureOutput(func() {
+		err = cmdList.RunE(cmdList, []string{})
+	})
+	if err != nil {
+		t.Fatalf("list --query: %v", err)
+	}
+	if !strings.Contains(output, "[2]") || !strings.Contains(output, "[3]") || strings.Contains(output, "[1]") || strings.Contains(output, "[4]") {
+		t.Errorf("Output list --query = %q, esperadas 2 y 3", output)
+	}
+
+	cmdList.Flags().Set("query", "otra")
+	if err := cmdList.RunE(cmdList, []string{}); exitCode(err) != exitValidation {
+		t.Errorf("list --query inexistente = %v, esperado error de validación", err)
+	}
+
+	captureOutput(func() {
+		err = cmdQueryRemove.RunE(cmdQueryRemove, []string{"urgentes"})
+	})
+	if queries, _ := loadSavedQueries(); err != nil || len(queries) != 0 {
+		t.Errorf("query rm = %v, quedan %v", err, queries)
+	}
+}
+
+func TestCmdSearch(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(searchTestTasks())
+
+	output := captureOutput(func() {
+		cmdSearch.RunE(cmdSearch, []string{"cancion"})
+	})
+	want := "[2] *Canción* de bienvenida (TODO)\n    Elegir la *canción* para la demo\n"
+	if output != want {
+		t.Errorf("Output search = %q, esperado %q", output, want)
+	}
+
+	output = captureOutput(func() {
+		cmdSearch.RunE(cmdSearch, []string{"nada", "parecido"})
+	})
+	if output != "No se encontraron tareas para \"nada parecido\".\n" {
+		t.Errorf("Output search sin resultados = %q", output)
+	}
+
+	if err := cmdSearch.RunE(cmdSearch, []string{`"sin cerrar`}); exitCode(err) != exitValidation {
+		t.Errorf("search con comillas sin cerrar = %v, esperado error de validación", err)
+	}
+}
+
+func TestCmdTimerAndLog(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks([]Task{NewTask(1, "Facturable", "")})
+
+	output := captureOutput(func() {
+		cmdStart.RunE(cmdStart, []string{"1"})
+	})
+	if !strings.Contains(output, "Temporizador iniciado") {
+		t.Errorf("Output start = %q", output)
+	}
+	var err error
+	captureOutput(func() {
+		err = cmdResume.RunE(cmdResume, []string{"1"})
+	})
+	if exitCode(err) != exitValidation {
+		t.Errorf("resume sin pausa = %v, esperado error de validación", err)
+	}
+	output = captureOutput(func() {
+		err = cmdPause.RunE(cmdPause, []string{"1"})
+	})
+	if err != nil || !strings.HasPrefix(output, "Temporizador en pausa en la tarea 1") {
+		t.Errorf("pause = %v, output %q", err, output)
+	}
+	captureOutput(func() {
+		err = cmdResume.RunE(cmdResume, []string{"1"})
+	})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	output = captureOutput(func() {
+		err = cmdLog.RunE(cmdLog, []string{"1", "1h30m", "code", "review"})
+	})
+	if err != nil || !strings.HasPrefix(output, "Registrado 1h30m en la tarea 1; total: 1h30m") {
+		t.Errorf("log = %v, output %q", err, output)
+	}
+	cmdLog.Flags().Set("date", "2026-01-05")
+	captureOutput(func() {
+		err = cmdLog.RunE(cmdLog, []string{"1", "45"})
+	})
+	if err != nil {
+		t.Fatalf("log --date: %v", err)
+	}
+	if err := cmdLog.RunE(cmdLog, []string{"1", "mucho"}); exitCode(err) != exitValidation {
+		t.Errorf("log con duración inválida = %v, esperado error de validación", err)
+	}
+
+	output = captureOutput(func() {
+		cmdDone.RunE(cmdDone, []string{"1"})
+	})
+	if !strings.Contains(output, "Temporizador detenido; total registrado: 2h15m") {
+		t.Errorf("Output done = %q", output)
+	}
+
+	output = captureOutput(func() {
+		cmdView.RunE(cmdView, []string{"1"})
+	})
+	if !strings.Contains(output, "Tiempo registrado: 2h15m\n") {
+		t.Errorf("Output view = %q", output)
+	}
+
+	output = captureOutput(func() {
+		cmdLog.RunE(cmdLog, []string{"1"})
+	})
+	if !strings.Contains(output, "2026-01-05 00:00     45m\n") || !strings.Contains(output, "1h30m  code review") || !strings.HasSuffix(output, "Total: 2h15m\n") {
+		t.Errorf("Output log = %q", output)
+	}
+}
+
+func TestCmdReport(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	now := time.Now()
+	tasks := []Task{NewTask(1, "Hecha", ""), NewTask(2, "Pendiente", "")}
+	tasks[0].Status = DONE
+	tasks[0].CompletedAt = &tasks[0].UpdatedAt
+	saveTasks(tasks)
+
+	cmdReport.Flags().Set("from", "today")
+	output := captureOutput(func() {
+		cmdReport.RunE(cmdReport, []string{})
+	})
+	want := "Periodo     Creadas  Iniciadas  Completadas  Lead time medio\n" +
+		now.Format("2006-01-02") + "  2        0          1            0h\n" +
+		"Total       2        0          1            0h\n"
+	if output != want {
+		t.Errorf("Output report = %q, esperado %q", output, want)
+	}
+
+	cmdReport.Flags().Set("group-by", "mes")
+	if err := cmdReport.RunE(cmdReport, []string{}); exitCode(err) != exitValidation {
+		t.Errorf("report --group-by mes = %v, esperado error de validación", err)
+	}
+	cmdReport.Flags().Set("group-by", "day")
+	cmdReport.Flags().Set("from", "tomorrow")
+	if err := cmdReport.RunE(cmdReport, []string{}); exitCode(err) != exitValidation {
+		t.Errorf("report con --from posterior a --to = %v, esperado error de validación", err)
+	}
+}
+
+func TestCmdDoneRecurring(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	cmdAdd.Flags().Set("title", "Backup")
+	cmdAdd.Flags().Set("repeat", "every 2 days")
+	captureOutput(func() {
+		if err := cmdAdd.RunE(cmdAdd, nil); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	})
+	tasks, _ := loadTasks()
+	if tasks[0].Recurrence != "FREQ=DAILY;INTERVAL=2" || tasks[0].Due == nil {
+		t.Fatalf("Tarea = %+v, esperada repetición con vencimiento", tasks[0])
+	}
+	due := *tasks[0].Due
+
+	output := captureOutput(func() {
+		cmdDone.RunE(cmdDone, []string{"1"})
+	})
+	if !strings.Contains(output, "Siguiente repetición: tarea 2") {
+		t.Errorf("Output done = %q", output)
+	}
+	tasks, _ = loadTasks()
+	if len(tasks) != 2 {
+		t.Fatalf("Tareas = %d, esperadas 2", len(tasks))
+	}
+	next := tasks[1]
+	if next.Status != TODO || next.Title != "Backup" || !next.Due.Equal(addDays(due, 2)) {
+		t.Errorf("Siguiente = %+v, esperada TODO con vencimiento %v", next, addDays(due, 2))
+	}
+	if len(next.Links) != 1 || next.Links[0] != (Link{Kind: seriesLinkKind, ID: 1}) {
+		t.Errorf("Links = %v, esperado [series 1]", next.Links)
+	}
+
+	// Completar de nuevo una tarea ya terminada no crea otra repetición.
+	captureOutput(func() {
+		cmdDone.RunE(cmdDone, []string{"1"})
+	})
+	if tasks, _ = loadTasks(); len(tasks) != 2 {
+		t.Errorf("Tareas = %d, esperadas 2", len(tasks))
+	}
+
+	cmdEdit.Flags().Set("repeat", "none")
+	captureOutput(func() {
+		if err := cmdEdit.RunE(cmdEdit, []string{"2"}); err != nil {
+			t.Fatalf("edit: %v", err)
+		}
+	})
+	if tasks, _ = loadTasks(); tasks[1].Recurrence != "" {
+		t.Errorf("Recurrence = %q, esperada vacía", tasks[1].Recurrence)
+	}
+}
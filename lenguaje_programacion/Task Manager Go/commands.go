@@ -3,10 +3,12 @@ package main
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"io"
 	"strings"
 
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -21,270 +23,1387 @@ var version = "v1.0.0"
 var cmdAdd = &cobra.Command{
 	Use:   "add",
 	Short: "Agregar una nueva tarea",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		title, _ := cmd.Flags().GetString("title")
 		desc, _ := cmd.Flags().GetString("desc")
 		if strings.TrimSpace(title) == "" {
-			fmt.Println("Error: --title es requerido")
-			_ = cmd.Help()
-			return
+			return validationErrorf("--title es requerido")
 		}
-		tasks, err := loadTasks()
+		task := NewTask(0, title, desc)
+		if p, _ := cmd.Flags().GetString("priority"); p != "" {
+			priority, err := parsePriority(p)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			task.Priority = priority
+		}
+		if d, _ := cmd.Flags().GetString("due"); d != "" {
+			due, err := parseDue(d, timeNow())
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			task.Due = &due
+		}
+		tags, _ := cmd.Flags().GetStringArray("tag")
+		task.Tags = addTags(nil, tags...)
+		p, _ := cmd.Flags().GetString("project")
+		project, err := parseProject(p)
 		if err != nil {
-			fmt.Println("Error cargando tareas:", err)
-			return
+			return &ValidationError{Msg: err.Error()}
 		}
-		t := NewTask(nextID(tasks), title, desc)
-		tasks = append(tasks, t)
-		if err := saveTasks(tasks); err != nil {
-			fmt.Println("Error guardando:", err)
-			return
+		task.Project = project
+		task.ParentID, _ = cmd.Flags().GetInt("parent")
+		if r, _ := cmd.Flags().GetString("repeat"); r != "" {
+			rule, err := parseRecurrence(r)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			task.Recurrence = rule.String()
+			if task.Due == nil {
+				due := rule.first(timeNow())
+				task.Due = &due
+			}
 		}
-		fmt.Printf("Tarea creada: ID=%d\n", t.ID)
+		return withStore(func(s Store) error {
+			_, err := addTask(os.Stdout, s, task)
+			return err
+		})
 	},
 }
 
+// addTask guarda una tarea nueva comprobando antes su tarea padre.
+func addTask(w io.Writer, s Store, task Task) (Task, error) {
+	if task.ParentID != 0 {
+		tasks, err := s.Load()
+		if err != nil {
+			return Task{}, storageError("cargando tareas", err)
+		}
+		if err := validateParent(tasks, 0, task.ParentID); err != nil {
+			return Task{}, &ValidationError{Msg: err.Error()}
+		}
+	}
+	t, err := s.Insert(task)
+	if err != nil {
+		return Task{}, storageError("guardando", err)
+	}
+	fmt.Fprintf(w, "Tarea creada: ID=%d\n", t.ID)
+	return t, nil
+}
+
 func init() {
 	cmdAdd.Flags().StringP("title", "t", "", "Título de la tarea (requerido)")
 	cmdAdd.Flags().StringP("desc", "d", "", "Descripción (opcional)")
+	cmdAdd.Flags().StringP("priority", "p", "", "Prioridad: low|medium|high|urgent o 1-4 (por defecto medium)")
+	cmdAdd.Flags().String("due", "", "Fecha de vencimiento: AAAA-MM-DD, today, tomorrow, next friday, in 3 days...")
+	cmdAdd.Flags().StringArray("tag", nil, "Etiqueta (repetible)")
+	cmdAdd.Flags().String("project", "", "Proyecto, con jerarquía separada por puntos (p. ej. backend.api)")
+	cmdAdd.Flags().Int("parent", 0, "ID de la tarea padre (crea una subtarea)")
+	cmdAdd.Flags().String("repeat", "", "Repetición: daily, weekly on mon,fri, monthly on 15, every 2 weeks o una RRULE")
 }
 
 var cmdList = &cobra.Command{
 	Use:   "list",
 	Short: "Listar tareas",
-	Run: func(cmd *cobra.Command, args []string) {
-		state, _ := cmd.Flags().GetString("state")
-		tasks, err := loadTasks()
-		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
-		}
-		if len(tasks) == 0 {
-			fmt.Println("No hay tareas.")
-			return
-		}
-		printed := 0
-		for _, t := range tasks {
-			show := false
-			switch state {
-			case "all":
-				show = true
-			case "todo":
-				show = (t.Status == TODO)
-			case "inprogress":
-				show = (t.Status == INPROGRESS)
-			case "done":
-				show = (t.Status == DONE)
-			default:
-				fmt.Println("Estado inválido. Usa: all|todo|inprogress|done")
-				return
-			}
-			if show {
-				fmt.Printf("[%d] %s (%s)\n", t.ID, t.Title, t.Status)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sortKey, _ := cmd.Flags().GetString("sort")
+		tree, _ := cmd.Flags().GetBool("tree")
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		if err := sortTasks(nil, sortKey); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		now := timeNow()
+		filter, err := parseFilterFlags(cmd, now)
+		if err != nil {
+			return err
+		}
+		return withStore(func(s Store) error {
+			tasks, err := filter.apply(s)
+			if err != nil {
+				return err
+			}
+			sortTasks(tasks, sortKey)
+			if outputFormat != defaultOutputFormat {
+				return writeTasks(os.Stdout, outputFormat, tasks, false)
+			}
+			if len(tasks) == 0 {
+				if filter.state == "all" && !filter.filtered() {
+					fmt.Println("No hay tareas.")
+				} else {
+					fmt.Println("No se encontraron tareas con ese filtro.")
+				}
+				return nil
+			}
+			depths := make([]int, len(tasks))
+			if tree {
+				tasks, depths = treeOrder(tasks)
+			}
+			for i, t := range tasks {
+				pad := indent(depths[i])
+				fmt.Printf("%s[%d] %s (%s)%s%s%s%s\n", pad, t.ID, t.Title, t.Status, priorityLabel(t.Priority), dueLabel(t, now), projectLabel(t.Project), tagsLabel(t.Tags))
 				if t.Description != "" {
-					fmt.Printf("    %s\n", t.Description)
+					fmt.Printf("%s    %s\n", pad, t.Description)
 				}
-				printed++
 			}
-		}
-		if printed == 0 {
-			fmt.Println("No se encontraron tareas con ese filtro.")
-		}
+			return nil
+		})
 	},
 }
 
 func init() {
-	cmdList.Flags().StringP("state", "s", "all", "Filtrar por estado: all|todo|inprogress|done")
+	addFilterFlags(cmdList)
+	cmdList.Flags().String("sort", "id", "Ordenar por: "+taskSortKeys)
+	cmdList.Flags().Bool("tree", false, "Mostrar las subtareas sangradas bajo su tarea padre")
+}
+
+func projectLabel(project string) string {
+	if project == "" {
+		return ""
+	}
+	return " @" + project
+}
+
+func tagsLabel(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return " #" + strings.Join(tags, " #")
+}
+
+func dueLabel(t Task, now time.Time) string {
+	if t.Due == nil {
+		return ""
+	}
+	if isOverdue(t, now) {
+		return " (venció " + formatDue(*t.Due) + ")"
+	}
+	return " (vence " + formatDue(*t.Due) + ")"
+}
+
+// priorityLabel solo muestra la prioridad cuando difiere de la predeterminada.
+func priorityLabel(p Priority) string {
+	if p == MEDIUM {
+		return ""
+	}
+	return " [" + p.String() + "]"
 }
 
 var cmdView = &cobra.Command{
 	Use:   "view <id>",
 	Short: "Ver detalles de una tarea",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Println("ID inválido:", args[0])
-			return
-		}
-		tasks, err := loadTasks()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
 		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
+			return err
 		}
-		i, err := findTaskIndexByID(tasks, id)
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("Tarea %d no encontrada\n", id)
-			return
-		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return &ValidationError{Msg: err.Error()}
 		}
-		t := tasks[i]
-		fmt.Printf("ID: %d\nTítulo: %s\nDescripción: %s\nEstado: %s\nCreado: %s\nActualizado: %s\n",
-			t.ID, t.Title, t.Description, t.Status, t.CreatedAt.Format("2006-01-02 15:04"), t.UpdatedAt.Format("2006-01-02 15:04"))
+		return withStore(func(s Store) error {
+			t, err := getTask(s, id)
+			if err != nil {
+				return err
+			}
+			if outputFormat != defaultOutputFormat {
+				return writeTasks(os.Stdout, outputFormat, []Task{t}, true)
+			}
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			writeTaskDetail(os.Stdout, t, tasks, timeNow())
+			return nil
+		})
 	},
 }
 
+// writeTaskDetail escribe la ficha de view; tasks es la lista completa, para
+// el progreso de las subtareas y las dependencias pendientes.
+func writeTaskDetail(w io.Writer, t Task, tasks []Task, now time.Time) {
+	fmt.Fprintf(w, "ID: %d\nTítulo: %s\nDescripción: %s\nEstado: %s\nPrioridad: %s\nCreado: %s\nActualizado: %s\n",
+		t.ID, t.Title, t.Description, t.Status, t.Priority, t.CreatedAt.Format("2006-01-02 15:04"), t.UpdatedAt.Format("2006-01-02 15:04"))
+	if t.StartedAt != nil {
+		fmt.Fprintf(w, "Iniciada: %s\n", t.StartedAt.Format("2006-01-02 15:04"))
+	}
+	if t.CompletedAt != nil {
+		fmt.Fprintf(w, "Completada: %s\n", t.CompletedAt.Format("2006-01-02 15:04"))
+	}
+	if t.Due != nil {
+		fmt.Fprintf(w, "Vence: %s\n", formatDue(*t.Due))
+	}
+	if t.Project != "" {
+		fmt.Fprintf(w, "Proyecto: %s\n", t.Project)
+	}
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(w, "Etiquetas: %s\n", strings.Join(t.Tags, ", "))
+	}
+	if t.ParentID != 0 {
+		fmt.Fprintf(w, "Tarea padre: %d\n", t.ParentID)
+	}
+	if progress := progressLabel(subtaskProgress(tasks, t.ID)); progress != "" {
+		fmt.Fprintf(w, "Progreso: %s\n", progress)
+	}
+	if len(t.BlockedBy) > 0 {
+		fmt.Fprintf(w, "Depende de: %s", joinIDs(t.BlockedBy, ", "))
+		if pending := pendingBlockers(tasks, t.ID); len(pending) > 0 {
+			fmt.Fprintf(w, " (pendientes: %s)", joinIDs(pending, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+	if len(t.Links) > 0 {
+		fmt.Fprintf(w, "Enlaces: %s\n", linksLabel(t.Links))
+	}
+	if t.Recurrence != "" {
+		fmt.Fprintf(w, "Repetición: %s\n", t.Recurrence)
+	}
+	if len(t.Worklog) > 0 {
+		fmt.Fprintf(w, "Tiempo registrado: %s%s\n", formatWorked(t.trackedTime(now)), timerLabel(t))
+	}
+}
+
+// updateTask aplica fn a la tarea id con Store.Modify, así que la lectura,
+// las comprobaciones de fn y el guardado no se intercalan con otro proceso.
+// Los errores de fn se devuelven tal cual.
+func updateTask(s Store, id int, fn func(t *Task) error) (Task, error) {
+	var fnErr error
+	t, err := s.Modify(id, func(t *Task) error {
+		fnErr = fn(t)
+		return fnErr
+	})
+	switch {
+	case fnErr != nil:
+		return Task{}, fnErr
+	case errors.Is(err, os.ErrNotExist):
+		return Task{}, &NotFoundError{ID: id}
+	case err != nil:
+		return Task{}, storageError("guardando", err)
+	}
+	return t, nil
+}
+
+// setStatus también inicia el temporizador al pasar a IN_PROGRESS y lo
+// detiene al pasar a DONE; completar una tarea recurrente crea la siguiente.
+func setStatus(w io.Writer, s Store, id int, status Status) error {
+	_, err := setStatusChecked(w, s, id, status, nil)
+	return err
+}
+
+// setStatusChecked es setStatus con una comprobación previa que se ejecuta
+// dentro de la misma modificación, p. ej. las dependencias pendientes de start
+// o el If-Match de la API. Devuelve la tarea guardada.
+func setStatusChecked(w io.Writer, s Store, id int, status Status, check func(t Task) error) (Task, error) {
+	now := timeNow()
+	var change statusChange
+	t, err := updateTask(s, id, func(t *Task) error {
+		if check != nil {
+			if err := check(*t); err != nil {
+				return err
+			}
+		}
+		change = applyStatus(t, status, now)
+		return nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return finishStatus(w, s, t, change, now)
+}
+
+// statusChange recuerda lo que hizo applyStatus para que finishStatus lo
+// informe después de guardar.
+type statusChange struct {
+	wasDone bool
+	timer   string
+}
+
+// applyStatus cambia el estado de t con sus efectos: el temporizador, que
+// arranca en IN_PROGRESS y se detiene al salir, y las horas de inicio y de
+// finalización. Lo usan setStatus y los cambios de status de la API dentro
+// de su propia modificación.
+func applyStatus(t *Task, status Status, now time.Time) statusChange {
+	change := statusChange{wasDone: t.Status == DONE}
+	t.Status = status
+	t.UpdatedAt = now
+	if status == INPROGRESS && t.StartedAt == nil {
+		t.StartedAt = &now
+	}
+	switch {
+	case status != DONE:
+		t.CompletedAt = nil
+	case !change.wasDone:
+		t.CompletedAt = &now
+	}
+	switch {
+	case status == INPROGRESS && !t.timerRunning():
+		t.startTimer(now)
+		change.timer = "Temporizador iniciado"
+	case status != INPROGRESS && (t.timerRunning() || t.timerPaused()):
+		// Volver a TODO o terminar cierra la sesión, también si estaba en pausa.
+		t.stopTimer(now)
+		change.timer = "Temporizador detenido; total registrado: " + formatWorked(t.trackedTime(now))
+	}
+	return change
+}
+
+// finishStatus informa del cambio ya guardado y, al completar una tarea
+// recurrente, crea la siguiente repetición.
+func finishStatus(w io.Writer, s Store, t Task, change statusChange, now time.Time) (Task, error) {
+	fmt.Fprintf(w, "Tarea %d marcada como %s\n", t.ID, t.Status)
+	if change.timer != "" {
+		fmt.Fprintln(w, change.timer)
+	}
+	if t.Status != DONE || change.wasDone || t.Recurrence == "" {
+		return t, nil
+	}
+	if _, ok := s.(statusApplyingStore); ok {
+		fmt.Fprintln(w, "El servidor crea la siguiente repetición")
+		return t, nil
+	}
+	next, ok, err := nextOccurrence(t, now)
+	if err != nil {
+		return Task{}, &ValidationError{Msg: fmt.Sprintf("tarea %d: %v", t.ID, err)}
+	}
+	if !ok {
+		fmt.Fprintln(w, "La serie de repeticiones ha terminado")
+		return t, nil
+	}
+	next, err = s.Insert(next)
+	if err != nil {
+		return Task{}, storageError("guardando la siguiente repetición", err)
+	}
+	fmt.Fprintf(w, "Siguiente repetición: tarea %d, vence %s\n", next.ID, formatDue(*next.Due))
+	return t, nil
+}
+
 var cmdStart = &cobra.Command{
 	Use:   "start <id>",
 	Short: "Marcar tarea como IN_PROGRESS",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
 		if err != nil {
-			fmt.Println("ID inválido:", args[0])
-			return
+			return err
 		}
-		tasks, err := loadTasks()
-		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
-		}
-		i, err := findTaskIndexByID(tasks, id)
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("Tarea %d no encontrada\n", id)
-			return
-		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-		tasks[i].Status = INPROGRESS
-		tasks[i].UpdatedAt = timeNow()
-		if err := saveTasks(tasks); err != nil {
-			fmt.Println("Error guardando:", err)
-			return
-		}
-		fmt.Printf("Tarea %d marcada como IN_PROGRESS\n", id)
+		force, _ := cmd.Flags().GetBool("force")
+		return withStore(func(s Store) error {
+			return startTask(os.Stdout, s, id, force)
+		})
 	},
 }
 
+// startTask pasa la tarea a IN_PROGRESS si no tiene dependencias pendientes
+// o si force es true.
+func startTask(w io.Writer, s Store, id int, force bool) error {
+	var check func(t Task) error
+	if !force {
+		check = func(t Task) error {
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			if pending := pendingBlockers(tasks, id); len(pending) > 0 {
+				return validationErrorf("la tarea %d está bloqueada por %s; usa --force para iniciarla igualmente", id, joinIDs(pending, ", "))
+			}
+			return nil
+		}
+	}
+	_, err := setStatusChecked(w, s, id, INPROGRESS, check)
+	return err
+}
+
+func init() {
+	cmdStart.Flags().BoolP("force", "f", false, "Iniciar aunque tenga dependencias sin terminar")
+}
+
 var cmdDone = &cobra.Command{
 	Use:   "done <id>",
 	Short: "Marcar tarea como DONE",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Println("ID inválido:", args[0])
-			return
-		}
-		tasks, err := loadTasks()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
 		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
+			return err
 		}
-		i, err := findTaskIndexByID(tasks, id)
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("Tarea %d no encontrada\n", id)
-			return
-		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-		tasks[i].Status = DONE
-		tasks[i].UpdatedAt = timeNow()
-		if err := saveTasks(tasks); err != nil {
-			fmt.Println("Error guardando:", err)
-			return
-		}
-		fmt.Printf("Tarea %d marcada como DONE\n", id)
+		return withStore(func(s Store) error {
+			return setStatus(os.Stdout, s, id, DONE)
+		})
 	},
 }
 
 var cmdEdit = &cobra.Command{
 	Use:   "edit <id>",
-	Short: "Editar título y/o descripción de una tarea",
+	Short: "Editar los campos de una tarea",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
 		if err != nil {
-			fmt.Println("ID inválido:", args[0])
-			return
+			return err
 		}
 
 		titleChanged := cmd.Flags().Changed("title")
 		descChanged := cmd.Flags().Changed("desc")
+		priorityChanged := cmd.Flags().Changed("priority")
+		dueChanged := cmd.Flags().Changed("due")
+		addTag, _ := cmd.Flags().GetStringArray("add-tag")
+		removeTag, _ := cmd.Flags().GetStringArray("remove-tag")
+		tagsChanged := len(addTag) > 0 || len(removeTag) > 0
+		projectChanged := cmd.Flags().Changed("project")
+		parentChanged := cmd.Flags().Changed("parent")
+		repeatChanged := cmd.Flags().Changed("repeat")
 
-		if !titleChanged && !descChanged {
-			fmt.Println("Debe especificar --title o --desc para editar")
-			_ = cmd.Help()
-			return
+		if !titleChanged && !descChanged && !priorityChanged && !dueChanged && !tagsChanged && !projectChanged && !parentChanged && !repeatChanged {
+			return validationErrorf("debe especificar --title, --desc, --priority, --due, --add-tag, --remove-tag, --project, --parent o --repeat para editar")
 		}
 
 		title, _ := cmd.Flags().GetString("title")
 		desc, _ := cmd.Flags().GetString("desc")
-
-		tasks, err := loadTasks()
-		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
-		}
-		i, err := findTaskIndexByID(tasks, id)
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("Tarea %d no encontrada\n", id)
-			return
-		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
+		var priority Priority
+		if priorityChanged {
+			p, _ := cmd.Flags().GetString("priority")
+			if priority, err = parsePriority(p); err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
 		}
-
-		if titleChanged {
-			tasks[i].Title = title
+		var due *time.Time
+		if dueChanged {
+			d, _ := cmd.Flags().GetString("due")
+			if d != "" && d != "none" {
+				parsed, err := parseDue(d, timeNow())
+				if err != nil {
+					return &ValidationError{Msg: err.Error()}
+				}
+				due = &parsed
+			}
 		}
-		if descChanged {
-			tasks[i].Description = desc
+		p, _ := cmd.Flags().GetString("project")
+		project, err := parseProject(p)
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
 		}
-		tasks[i].UpdatedAt = timeNow()
-		if err := saveTasks(tasks); err != nil {
-			fmt.Println("Error guardando:", err)
-			return
+		parent, _ := cmd.Flags().GetInt("parent")
+		var recurrence string
+		if r, _ := cmd.Flags().GetString("repeat"); repeatChanged && r != "" && r != "none" {
+			rule, err := parseRecurrence(r)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			recurrence = rule.String()
 		}
-		fmt.Printf("Tarea %d actualizada\n", id)
+
+		return withStore(func(s Store) error {
+			_, err := updateTask(s, id, func(t *Task) error {
+				if parentChanged {
+					tasks, err := s.Load()
+					if err != nil {
+						return storageError("cargando tareas", err)
+					}
+					if err := validateParent(tasks, id, parent); err != nil {
+						return &ValidationError{Msg: err.Error()}
+					}
+				}
+				if titleChanged {
+					t.Title = title
+				}
+				if descChanged {
+					t.Description = desc
+				}
+				if priorityChanged {
+					t.Priority = priority
+				}
+				if dueChanged {
+					t.Due = due
+				}
+				if tagsChanged {
+					t.Tags = addTags(removeTags(t.Tags, removeTag...), addTag...)
+				}
+				if projectChanged {
+					t.Project = project
+				}
+				if parentChanged {
+					t.ParentID = parent
+				}
+				if repeatChanged {
+					t.Recurrence = recurrence
+				}
+				t.UpdatedAt = timeNow()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Tarea %d actualizada\n", id)
+			return nil
+		})
 	},
 }
 
 func init() {
 	cmdEdit.Flags().StringP("title", "t", "", "Nuevo título")
 	cmdEdit.Flags().StringP("desc", "d", "", "Nueva descripción")
+	cmdEdit.Flags().StringP("priority", "p", "", "Nueva prioridad: low|medium|high|urgent o 1-4")
+	cmdEdit.Flags().String("due", "", "Nueva fecha de vencimiento (none para quitarla)")
+	cmdEdit.Flags().StringArray("add-tag", nil, "Agregar etiqueta (repetible)")
+	cmdEdit.Flags().StringArray("remove-tag", nil, "Quitar etiqueta (repetible)")
+	cmdEdit.Flags().String("project", "", "Nuevo proyecto (none para quitarlo)")
+	cmdEdit.Flags().Int("parent", 0, "Nueva tarea padre (0 para convertirla en tarea principal)")
+	cmdEdit.Flags().String("repeat", "", "Nueva repetición (none para quitarla)")
 }
 
 var cmdRemove = &cobra.Command{
 	Use:   "rm <id>",
 	Short: "Eliminar tarea",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		id, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Println("ID inválido:", args[0])
-			return
-		}
-		tasks, err := loadTasks()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
 		if err != nil {
-			fmt.Println("Error cargando:", err)
-			return
+			return err
 		}
-		i, err := findTaskIndexByID(tasks, id)
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Printf("Tarea %d no encontrada\n", id)
-			return
-		} else if err != nil {
-			fmt.Println("Error:", err)
-			return
-		}
-		tasks = append(tasks[:i], tasks[i+1:]...)
-		if err := saveTasks(tasks); err != nil {
-			fmt.Println("Error guardando:", err)
-			return
-		}
-		fmt.Printf("Tarea %d eliminada\n", id)
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		return withStore(func(s Store) error {
+			return removeTask(os.Stdout, s, id, recursive)
+		})
 	},
 }
 
+// removeTask elimina la tarea id y, con recursive, también sus subtareas.
+func removeTask(w io.Writer, s Store, id int, recursive bool) error {
+	return removeTaskChecked(w, s, id, func(t Task, children []int) error {
+		if len(children) > 0 && !recursive {
+			return validationErrorf("la tarea %d tiene %d subtareas; usa --recursive para eliminarlas también", id, len(children))
+		}
+		return nil
+	})
+}
+
+// removeTaskChecked elimina la tarea id con todas sus subtareas en una sola
+// escritura si check lo permite; check se ejecuta con el almacenamiento
+// bloqueado y sus errores se devuelven tal cual.
+func removeTaskChecked(w io.Writer, s Store, id int, check func(t Task, children []int) error) error {
+	var checkErr error
+	children, err := s.DeleteTree(id, func(t Task, children []int) error {
+		checkErr = check(t, children)
+		return checkErr
+	})
+	switch {
+	case checkErr != nil:
+		return checkErr
+	case errors.Is(err, os.ErrNotExist):
+		return &NotFoundError{ID: id}
+	case err != nil:
+		return storageError("guardando", err)
+	}
+	if len(children) > 0 {
+		fmt.Fprintf(w, "Tarea %d eliminada junto con %d subtareas\n", id, len(children))
+		return nil
+	}
+	fmt.Fprintf(w, "Tarea %d eliminada\n", id)
+	return nil
+}
+
+func init() {
+	cmdRemove.Flags().BoolP("recursive", "r", false, "Eliminar también las subtareas")
+}
+
 var cmdVersion = &cobra.Command{
 	Use:   "version",
 	Short: "Mostrar versión",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("taskcli", version)
+		if storeBackend == remoteStoreBackend {
+			fmt.Printf("Almacenamiento: %s (%s)\n", storeBackend, remoteAddr)
+			return nil
+		}
+		loc, err := resolveTasksLocation()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Almacenamiento: %s (%s, %s)\n", storeBackend, loc.Path, loc.Source)
+		return nil
+	},
+}
+
+var cmdInfo = &cobra.Command{
+	Use:   "info",
+	Short: "Mostrar el almacenamiento y la configuración activos",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgPath, err := configFilePath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(cfgPath); errors.Is(err, os.ErrNotExist) {
+			cfgPath += " (no existe)"
+		}
+		if storeBackend == remoteStoreBackend {
+			fmt.Printf("Almacenamiento: %s\nServidor: %s\nConfiguración: %s\n", storeBackend, remoteAddr, cfgPath)
+			return nil
+		}
+		loc, err := resolveTasksLocation()
+		if err != nil {
+			return err
+		}
+		path, err := activeStorePath()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Almacenamiento: %s\nArchivo: %s\nOrigen: %s\nConfiguración: %s\n", storeBackend, path, loc.Source, cfgPath)
+		return nil
+	},
+}
+
+var cmdInit = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "Crear una lista de tareas local (.taskcli/tasks.json) en el directorio",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		path, err := initProject(dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Lista de tareas local creada en %s\n", path)
+		return nil
+	},
+}
+
+var cmdMigrate = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrar tasks.json a la base de datos SQLite",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		force, _ := cmd.Flags().GetBool("force")
+		if from == "" {
+			path, err := tasksFilePath()
+			if err != nil {
+				return storageError("resolviendo archivo de tareas", err)
+			}
+			from = path
+		}
+		src := &jsonStore{path: from}
+		tasks, err := src.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		lastID, err := src.lastID(tasks)
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		path, err := sqliteFilePath()
+		if err != nil {
+			return storageError("resolviendo base de datos", err)
+		}
+		db, err := openSQLiteStore(path)
+		if err != nil {
+			return storageError("abriendo base de datos", err)
+		}
+		defer db.Close()
+		// El bloqueo cubre la comprobación y el guardado, para que dos migrate
+		// a la vez no reemplacen cada uno las tareas que acaba de copiar el otro.
+		lock, err := acquireLock(path+".lock", lockTimeout)
+		if err != nil {
+			return storageError("bloqueando base de datos", err)
+		}
+		defer lock.Release()
+		existing, err := db.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		if len(existing) > 0 && !force {
+			return validationErrorf("la base de datos %s ya contiene %d tareas; usa --force para reemplazarlas", path, len(existing))
+		}
+		if err := db.Save(tasks); err != nil {
+			return storageError("guardando", err)
+		}
+		// Los IDs de tareas ya eliminadas tampoco se reutilizan en SQLite.
+		if err := db.raiseLastID(lastID); err != nil {
+			return storageError("guardando", err)
+		}
+		fmt.Printf("Migradas %d tareas de %s a %s\n", len(tasks), from, path)
+		fmt.Println("Usa --store sqlite para trabajar con la base de datos")
+		return nil
+	},
+}
+
+func init() {
+	cmdMigrate.Flags().String("from", "", "Archivo JSON de origen (por defecto el archivo de tareas activo)")
+	cmdMigrate.Flags().Bool("force", false, "Reemplazar las tareas existentes en la base de datos")
+}
+
+var cmdRepair = &cobra.Command{
+	Use:   "repair",
+	Short: "Restaurar tasks.json desde la última copia válida",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := tasksFilePath()
+		if err != nil {
+			return storageError("resolviendo archivo de tareas", err)
+		}
+		bak, err := repairTasksFile(path)
+		if err != nil {
+			return storageError("reparando", err)
+		}
+		if bak == "" {
+			fmt.Printf("%s está en buen estado\n", path)
+			return nil
+		}
+		fmt.Printf("%s restaurado desde %s (el archivo dañado se guardó en %s.corrupt)\n", path, bak, path)
+		return nil
+	},
+}
+
+var cmdAgenda = &cobra.Command{
+	Use:   "agenda",
+	Short: "Mostrar las tareas pendientes agrupadas por vencimiento",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withStore(func(s Store) error {
+			tasks, err := s.Query(TaskQuery{
+				Statuses: []Status{TODO, INPROGRESS},
+				Match:    func(t Task) bool { return t.Due != nil },
+			})
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			if len(tasks) == 0 {
+				fmt.Println("No hay tareas pendientes con fecha de vencimiento.")
+				return nil
+			}
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Due.Before(*tasks[j].Due) })
+			now := timeNow()
+			groups := make([][]Task, len(agendaTitles))
+			for _, t := range tasks {
+				b := agendaBucket(*t.Due, now)
+				groups[b] = append(groups[b], t)
+			}
+			first := true
+			for i, group := range groups {
+				if len(group) == 0 {
+					continue
+				}
+				if !first {
+					fmt.Println()
+				}
+				first = false
+				fmt.Printf("%s:\n", agendaTitles[i])
+				for _, t := range group {
+					fmt.Printf("  %s  [%d] %s (%s)%s\n", formatDue(*t.Due), t.ID, t.Title, t.Status, priorityLabel(t.Priority))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var cmdTags = &cobra.Command{
+	Use:   "tags",
+	Short: "Listar las etiquetas con su número de tareas",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withStore(func(s Store) error {
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			counts := map[string]int{}
+			for _, t := range tasks {
+				for _, tag := range t.Tags {
+					counts[normalizeTag(tag)]++
+				}
+			}
+			if len(counts) == 0 {
+				fmt.Println("No hay etiquetas.")
+				return nil
+			}
+			names := make([]string, 0, len(counts))
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(i, j int) bool {
+				if counts[names[i]] != counts[names[j]] {
+					return counts[names[i]] > counts[names[j]]
+				}
+				return names[i] < names[j]
+			})
+			for _, name := range names {
+				fmt.Printf("%-20s %d\n", name, counts[name])
+			}
+			return nil
+		})
+	},
+}
+
+type statusCounts [3]int
+
+var cmdProjects = &cobra.Command{
+	Use:   "projects",
+	Short: "Listar los proyectos con sus tareas por estado, acumuladas por jerarquía",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withStore(func(s Store) error {
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			counts := map[string]*statusCounts{}
+			var none statusCounts
+			for _, t := range tasks {
+				if t.Project == "" {
+					none[t.Status]++
+					continue
+				}
+				for _, p := range projectAncestors(t.Project) {
+					if counts[p] == nil {
+						counts[p] = &statusCounts{}
+					}
+					counts[p][t.Status]++
+				}
+			}
+			if len(counts) == 0 {
+				fmt.Println("No hay proyectos.")
+				return nil
+			}
+			names := make([]string, 0, len(counts))
+			for name := range counts {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(i, j int) bool {
+				return strings.ReplaceAll(names[i], ".", "\x00") < strings.ReplaceAll(names[j], ".", "\x00")
+			})
+			fmt.Printf("%-30s %6s %12s %6s\n", "PROYECTO", "TODO", "IN_PROGRESS", "DONE")
+			for _, name := range names {
+				depth := strings.Count(name, ".")
+				label := strings.Repeat("  ", depth) + name[strings.LastIndex(name, ".")+1:]
+				c := counts[name]
+				fmt.Printf("%-30s %6d %12d %6d\n", label, c[TODO], c[INPROGRESS], c[DONE])
+			}
+			if none != (statusCounts{}) {
+				fmt.Printf("%-30s %6d %12d %6d\n", "(sin proyecto)", none[TODO], none[INPROGRESS], none[DONE])
+			}
+			return nil
+		})
+	},
+}
+
+func parseIDs(args []string) ([]int, error) {
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := parseID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+var cmdDepends = &cobra.Command{
+	Use:   "depends <id> <id-bloqueante>...",
+	Short: "Marcar que una tarea depende de otras",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIDs(args)
+		if err != nil {
+			return err
+		}
+		id, blockers := ids[0], ids[1:]
+		return withStore(func(s Store) error {
+			_, err := updateTask(s, id, func(t *Task) error {
+				tasks, err := s.Load()
+				if err != nil {
+					return storageError("cargando tareas", err)
+				}
+				i, err := findTaskIndexByID(tasks, id)
+				if err != nil {
+					return &NotFoundError{ID: id}
+				}
+				for _, b := range blockers {
+					if err := validateDependency(tasks, id, b); err != nil {
+						return &ValidationError{Msg: err.Error()}
+					}
+					tasks[i].BlockedBy = addBlockers(tasks[i].BlockedBy, b)
+				}
+				t.BlockedBy = addBlockers(t.BlockedBy, blockers...)
+				t.UpdatedAt = timeNow()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Tarea %d depende de %s\n", id, joinIDs(blockers, ", "))
+			return nil
+		})
+	},
+}
+
+var cmdUndepend = &cobra.Command{
+	Use:   "undepend <id> <id-bloqueante>...",
+	Short: "Quitar dependencias de una tarea",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIDs(args)
+		if err != nil {
+			return err
+		}
+		id, blockers := ids[0], ids[1:]
+		return withStore(func(s Store) error {
+			_, err := updateTask(s, id, func(t *Task) error {
+				t.BlockedBy = removeBlockers(t.BlockedBy, blockers...)
+				t.UpdatedAt = timeNow()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Tarea %d ya no depende de %s\n", id, joinIDs(blockers, ", "))
+			return nil
+		})
+	},
+}
+
+var cmdLink = &cobra.Command{
+	Use:   "link <id> <otro-id>",
+	Short: "Enlazar una tarea con otra (related, duplicates, ...)",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIDs(args)
+		if err != nil {
+			return err
+		}
+		kindFlag, _ := cmd.Flags().GetString("kind")
+		kind, err := normalizeLinkKind(kindFlag)
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		id, other := ids[0], ids[1]
+		if id == other {
+			return validationErrorf("una tarea no puede enlazarse consigo misma")
+		}
+		return withStore(func(s Store) error {
+			_, err := updateTask(s, id, func(t *Task) error {
+				if _, err := getTask(s, other); err != nil {
+					return err
+				}
+				t.Links = addLink(t.Links, Link{Kind: kind, ID: other})
+				t.UpdatedAt = timeNow()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Tarea %d enlazada con %d (%s)\n", id, other, kind)
+			return nil
+		})
+	},
+}
+
+var cmdUnlink = &cobra.Command{
+	Use:   "unlink <id> <otro-id>",
+	Short: "Quitar los enlaces de una tarea con otra",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := parseIDs(args)
+		if err != nil {
+			return err
+		}
+		kind, _ := cmd.Flags().GetString("kind")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		id, other := ids[0], ids[1]
+		return withStore(func(s Store) error {
+			_, err := updateTask(s, id, func(t *Task) error {
+				t.Links = removeLinks(t.Links, other, kind)
+				t.UpdatedAt = timeNow()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Tarea %d ya no está enlazada con %d\n", id, other)
+			return nil
+		})
+	},
+}
+
+func init() {
+	cmdLink.Flags().String("kind", defaultLinkKind, "Tipo de enlace")
+	cmdUnlink.Flags().String("kind", "", "Quitar solo los enlaces de este tipo")
+}
+
+var cmdGraph = &cobra.Command{
+	Use:   "graph",
+	Short: "Exportar las tareas y sus relaciones como diagrama (" + graphFormats + ")",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if err := validateGraphOptions(format, groupBy); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		filter, err := parseFilterFlags(cmd, timeNow())
+		if err != nil {
+			return err
+		}
+		return withStore(func(s Store) error {
+			tasks, err := filter.apply(s)
+			if err != nil {
+				return err
+			}
+			sortTasks(tasks, "id")
+			return writeGraph(os.Stdout, format, groupBy, tasks)
+		})
 	},
 }
+
+func init() {
+	addFilterFlags(cmdGraph)
+	cmdGraph.Flags().String("format", "dot", "Formato del diagrama: "+graphFormats)
+	cmdGraph.Flags().String("group-by", "project", "Agrupar en clusters por: "+graphGroupings)
+}
+
+var cmdQuery = &cobra.Command{
+	Use:   "query",
+	Short: "Gestionar consultas guardadas para list --query",
+}
+
+var cmdQuerySave = &cobra.Command{
+	Use:   "save <nombre> <expresión>",
+	Short: "Guardar una expresión de --where con un nombre",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, expr := args[0], strings.Join(args[1:], " ")
+		if err := validateQueryName(name); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		if _, err := parseQuery(expr, timeNow()); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		queries, err := loadSavedQueries()
+		if err != nil {
+			return err
+		}
+		_, existed := queries[name]
+		queries[name] = expr
+		if err := saveSavedQueries(queries); err != nil {
+			return storageError("guardando consultas", err)
+		}
+		if existed {
+			fmt.Printf("Consulta %q actualizada\n", name)
+		} else {
+			fmt.Printf("Consulta %q guardada; úsala con: taskcli list --query %s\n", name, name)
+		}
+		return nil
+	},
+}
+
+var cmdQueryList = &cobra.Command{
+	Use:   "list",
+	Short: "Listar las consultas guardadas",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queries, err := loadSavedQueries()
+		if err != nil {
+			return err
+		}
+		if len(queries) == 0 {
+			fmt.Println("No hay consultas guardadas.")
+			return nil
+		}
+		names := make([]string, 0, len(queries))
+		for name := range queries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: %s\n", name, queries[name])
+		}
+		return nil
+	},
+}
+
+var cmdQueryRemove = &cobra.Command{
+	Use:   "rm <nombre>",
+	Short: "Eliminar una consulta guardada",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queries, err := loadSavedQueries()
+		if err != nil {
+			return err
+		}
+		if _, ok := queries[args[0]]; !ok {
+			return validationErrorf("no existe la consulta guardada %q", args[0])
+		}
+		delete(queries, args[0])
+		if err := saveSavedQueries(queries); err != nil {
+			return storageError("guardando consultas", err)
+		}
+		fmt.Printf("Consulta %q eliminada\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	cmdQuery.AddCommand(cmdQuerySave, cmdQueryList, cmdQueryRemove)
+}
+
+var cmdSearch = &cobra.Command{
+	Use:   "search <términos>...",
+	Short: "Buscar en títulos y descripciones (\"frase exacta\", prefijo*)",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		if err := validateOutputFormat(outputFormat); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		clauses, err := parseSearchQuery(strings.Join(args, " "))
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		return withStore(func(s Store) error {
+			var ix termIndex
+			if indexed, ok := s.(indexedStore); ok {
+				ix, err = indexed.SearchIndex()
+			} else {
+				var tasks []Task
+				tasks, err = s.Load()
+				ix = buildIndex(tasks)
+			}
+			if err != nil {
+				return storageError("leyendo el índice de búsqueda", err)
+			}
+			hits, err := searchTasks(ix, clauses)
+			if err != nil {
+				return storageError("buscando", err)
+			}
+			if limit > 0 && len(hits) > limit {
+				hits = hits[:limit]
+			}
+			ids := make([]int, len(hits))
+			for i, h := range hits {
+				ids[i] = h.ID
+			}
+			found := []Task{}
+			if len(ids) > 0 {
+				if found, err = s.Query(TaskQuery{IDs: ids}); err != nil {
+					return storageError("cargando tareas", err)
+				}
+			}
+			byID := map[int]Task{}
+			for _, t := range found {
+				byID[t.ID] = t
+			}
+			tasks := make([]Task, 0, len(hits))
+			for _, id := range ids {
+				if t, ok := byID[id]; ok {
+					tasks = append(tasks, t)
+				}
+			}
+			if outputFormat != defaultOutputFormat {
+				return writeTasks(os.Stdout, outputFormat, tasks, false)
+			}
+			if len(tasks) == 0 {
+				fmt.Printf("No se encontraron tareas para %q.\n", strings.Join(args, " "))
+				return nil
+			}
+			open, end := "*", "*"
+			if isTerminal(os.Stdout) {
+				open, end = "\x1b[1;33m", "\x1b[0m"
+			}
+			for _, t := range tasks {
+				fmt.Printf("[%d] %s (%s)\n", t.ID, highlight(t.Title, clauses, open, end), t.Status)
+				if t.Description != "" {
+					fmt.Printf("    %s\n", highlight(snippet(t.Description, clauses, 80), clauses, open, end))
+				}
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	cmdSearch.Flags().IntP("limit", "n", 20, "Número máximo de resultados (0 = sin límite)")
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func timerLabel(t Task) string {
+	switch {
+	case t.timerRunning():
+		return " (temporizador en marcha)"
+	case t.timerPaused():
+		return " (temporizador en pausa)"
+	}
+	return ""
+}
+
+// timerCommand crea pause, resume y stop, que solo difieren en la acción.
+func timerCommand(use, short, done string, action func(t *Task, now time.Time) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return withStore(func(s Store) error {
+				now := timeNow()
+				t, err := updateTask(s, id, func(t *Task) error {
+					if err := action(t, now); err != nil {
+						return validationErrorf("tarea %d: %v", id, err)
+					}
+					t.UpdatedAt = now
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%s en la tarea %d; total registrado: %s\n", done, id, formatWorked(t.trackedTime(now)))
+				return nil
+			})
+		},
+	}
+}
+
+var cmdPause = timerCommand("pause", "Pausar el temporizador de una tarea", "Temporizador en pausa", (*Task).pauseTimer)
+
+var cmdResume = timerCommand("resume", "Reanudar el temporizador en pausa de una tarea", "Temporizador reanudado", (*Task).resumeTimer)
+
+var cmdStop = timerCommand("stop", "Detener el temporizador de una tarea", "Temporizador detenido", (*Task).stopTimer)
+
+var cmdLog = &cobra.Command{
+	Use:   "log <id> [<duración> [nota]]",
+	Short: "Registrar tiempo a mano (log 12 1h30m \"code review\") o ver el registro",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseID(args[0])
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			return withStore(func(s Store) error {
+				t, err := getTask(s, id)
+				if err != nil {
+					return err
+				}
+				printWorklog(t, timeNow())
+				return nil
+			})
+		}
+		d, err := parseWorkDuration(args[1])
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		note := strings.Join(args[2:], " ")
+		dateFlag, _ := cmd.Flags().GetString("date")
+		now := timeNow()
+		end := now
+		if dateFlag != "" {
+			date, err := parseDue(dateFlag, now)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			// Con solo una fecha, el intervalo empieza al inicio de ese día.
+			end = date
+			if date.Equal(endOfDay(date)) {
+				end = startOfDay(date).Add(d)
+			}
+		}
+		return withStore(func(s Store) error {
+			t, err := updateTask(s, id, func(t *Task) error {
+				t.logWork(end, d, note)
+				t.UpdatedAt = now
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Registrado %s en la tarea %d; total: %s\n", formatWorked(d), id, formatWorked(t.trackedTime(now)))
+			return nil
+		})
+	},
+}
+
+func init() {
+	cmdLog.Flags().String("date", "", "Fecha del trabajo registrado (por defecto, termina ahora)")
+}
+
+func printWorklog(t Task, now time.Time) {
+	if len(t.Worklog) == 0 {
+		fmt.Printf("La tarea %d no tiene tiempo registrado.\n", t.ID)
+		return
+	}
+	fmt.Printf("Registro de tiempo de la tarea %d:\n", t.ID)
+	for _, e := range t.Worklog {
+		state := ""
+		switch {
+		case e.End == nil:
+			state = " (en marcha)"
+		case e.Paused:
+			state = " (en pausa)"
+		}
+		line := fmt.Sprintf("  %s  %6s%s", e.Start.In(now.Location()).Format("2006-01-02 15:04"), formatWorked(e.duration(now)), state)
+		if e.Note != "" {
+			line += "  " + e.Note
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("Total: %s\n", formatWorked(t.trackedTime(now)))
+}
+
+var cmdReport = &cobra.Command{
+	Use:   "report",
+	Short: "Resumir tareas creadas, iniciadas y completadas por periodo",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if err := validateReportGrouping(groupBy); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		switch outputFormat {
+		case "text", "csv", "json", "yaml":
+		default:
+			return validationErrorf("el informe admite los formatos text, csv, json o yaml, no %s", outputFormat)
+		}
+		now := timeNow()
+		to := now
+		if toFlag != "" {
+			d, err := parseDue(toFlag, now)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			to = d
+		}
+		from := addDays(to, -29)
+		if fromFlag != "" {
+			d, err := parseDue(fromFlag, now)
+			if err != nil {
+				return &ValidationError{Msg: err.Error()}
+			}
+			from = d
+		}
+		if startOfDay(from).After(startOfDay(to)) {
+			return validationErrorf("--from (%s) es posterior a --to (%s)", from.Format("2006-01-02"), to.Format("2006-01-02"))
+		}
+		return withStore(func(s Store) error {
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			return writeReport(os.Stdout, outputFormat, buildReport(tasks, from, to, groupBy, now.Location()))
+		})
+	},
+}
+
+func init() {
+	cmdReport.Flags().String("from", "", "Primer día del informe (por defecto, 30 días antes de --to)")
+	cmdReport.Flags().String("to", "", "Último día del informe (por defecto, hoy)")
+	cmdReport.Flags().String("group-by", "day", "Agrupar por: "+reportGroupings)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+)
+
+const (
+	homeEnvVar  = "TASKCLI_HOME"
+	tokenEnvVar = "TASKCLI_TOKEN"
+)
+
+var dataFile string
+
+type Config struct {
+	File        string `toml:"file"`
+	Store       string `toml:"store"`
+	LockTimeout string `toml:"lock_timeout"`
+	Remote      string `toml:"remote"`
+	RemoteCA    string `toml:"remote_ca"`
+	Token       string `toml:"token"`
+}
+
+func configFilePath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "taskcli", "config.toml"), nil
+}
+
+func loadConfig() (Config, error) {
+	var cfg Config
+	path, err := configFilePath()
+	if err != nil {
+		return cfg, err
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, validationErrorf("configuración inválida en %s: %v", path, err)
+	}
+	if cfg.File != "" {
+		cfg.File = expandPath(cfg.File, filepath.Dir(path))
+	}
+	if cfg.RemoteCA != "" {
+		cfg.RemoteCA = expandPath(cfg.RemoteCA, filepath.Dir(path))
+	}
+	return cfg, nil
+}
+
+// expandPath resuelve "~/" y las rutas relativas respecto a base.
+func expandPath(path, base string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return path
+}
+
+// applyConfig completa con config.toml las opciones globales que no se
+// indicaron explícitamente en la línea de comandos.
+func applyConfig(flags *pflag.FlagSet) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Store != "" && !flags.Changed("store") {
+		storeBackend = cfg.Store
+	}
+	// --remote implica --store remote; la clave remote solo lo hace si la
+	// configuración no elige otro almacenamiento.
+	if cfg.Remote != "" && !flags.Changed("remote") {
+		remoteAddr = cfg.Remote
+	}
+	switch {
+	case flags.Changed("remote"):
+		if flags.Changed("store") && storeBackend != remoteStoreBackend {
+			return validationErrorf("--remote no se puede combinar con --store %s", storeBackend)
+		}
+		storeBackend = remoteStoreBackend
+	case cfg.Remote != "" && cfg.Store == "" && !flags.Changed("store"):
+		storeBackend = remoteStoreBackend
+	}
+	if cfg.RemoteCA != "" && !flags.Changed("remote-ca") {
+		remoteCA = cfg.RemoteCA
+	}
+	if !flags.Changed("token") {
+		if token := os.Getenv(tokenEnvVar); token != "" {
+			authToken = token
+		} else if cfg.Token != "" {
+			authToken = cfg.Token
+		}
+	}
+	if cfg.LockTimeout != "" && !flags.Changed("lock-timeout") {
+		d, err := time.ParseDuration(cfg.LockTimeout)
+		if err != nil {
+			return validationErrorf("lock_timeout inválido en la configuración: %v", err)
+		}
+		lockTimeout = d
+	}
+	return nil
+}
+
+type tasksLocation struct {
+	Path   string
+	Source string
+}
+
+// resolveTasksLocation elige el archivo de datos: --file, luego $TASKCLI_HOME,
+// luego una lista de proyecto (.taskcli/tasks.json en el directorio actual o
+// sus padres), la clave file de config.toml y por último $HOME/.taskcli/tasks.json.
+func resolveTasksLocation() (tasksLocation, error) {
+	if dataFile != "" {
+		path, err := filepath.Abs(dataFile)
+		return tasksLocation{Path: path, Source: "--file"}, err
+	}
+	if dir := os.Getenv(homeEnvVar); dir != "" {
+		dir, err := filepath.Abs(expandPath(dir, "."))
+		if err != nil {
+			return tasksLocation{}, err
+		}
+		return tasksLocation{Path: filepath.Join(dir, storeFileName), Source: "$" + homeEnvVar}, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return tasksLocation{}, err
+	}
+	global := filepath.Join(home, storeDirName, storeFileName)
+	// Desde cualquier directorio bajo $HOME la búsqueda de proyecto llega a la
+	// lista global, que no es de ningún proyecto: no debe tapar config.toml.
+	if cwd, err := os.Getwd(); err == nil {
+		if path, ok := findProjectTasksFile(cwd); ok && !sameFile(path, global) {
+			return tasksLocation{Path: path, Source: "proyecto"}, nil
+		}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return tasksLocation{}, err
+	}
+	if cfg.File != "" {
+		return tasksLocation{Path: cfg.File, Source: "config.toml"}, nil
+	}
+	return tasksLocation{Path: global, Source: "global"}, nil
+}
+
+// sameFile compara por archivo y no por ruta, porque $HOME puede ser un enlace
+// simbólico y el directorio actual viene ya resuelto.
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func resolveTasksFile() (string, error) {
+	loc, err := resolveTasksLocation()
+	return loc.Path, err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func writeTestConfig(t *testing.T, content string) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "taskcli", "config.toml")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Error escribiendo configuración: %v", err)
+	}
+	return path
+}
+
+func TestResolveTasksFilePrecedence(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(homeEnvVar, "")
+
+	path, err := resolveTasksFile()
+	if err != nil {
+		t.Fatalf("Error resolviendo: %v", err)
+	}
+	if expected := filepath.Join(home, storeDirName, storeFileName); path != expected {
+		t.Errorf("Por defecto = %s, esperado %s", path, expected)
+	}
+
+	cfgPath := writeTestConfig(t, `file = "proyecto/tareas.json"`)
+	path, _ = resolveTasksFile()
+	if expected := filepath.Join(filepath.Dir(cfgPath), "proyecto", "tareas.json"); path != expected {
+		t.Errorf("Con config.toml = %s, esperado %s", path, expected)
+	}
+
+	envDir := t.TempDir()
+	t.Setenv(homeEnvVar, envDir)
+	path, _ = resolveTasksFile()
+	if expected := filepath.Join(envDir, storeFileName); path != expected {
+		t.Errorf("Con %s = %s, esperado %s", homeEnvVar, path, expected)
+	}
+
+	flagFile := filepath.Join(t.TempDir(), "flag.json")
+	dataFile = flagFile
+	defer func() { dataFile = "" }()
+	path, _ = resolveTasksFile()
+	if path != flagFile {
+		t.Errorf("Con --file = %s, esperado %s", path, flagFile)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	writeTestConfig(t, "store = \"sqlite\"\nlock_timeout = \"2s\"\n")
+	defer func() {
+		storeBackend = defaultStoreBackend
+		lockTimeout = defaultLockTimeout
+	}()
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&storeBackend, "store", defaultStoreBackend, "")
+	flags.DurationVar(&lockTimeout, "lock-timeout", defaultLockTimeout, "")
+	flags.Parse([]string{"--store", "json"})
+
+	if err := applyConfig(flags); err != nil {
+		t.Fatalf("Error aplicando configuración: %v", err)
+	}
+	if storeBackend != "json" {
+		t.Errorf("store = %s, la bandera debe tener prioridad sobre config.toml", storeBackend)
+	}
+	if lockTimeout != 2*time.Second {
+		t.Errorf("lock_timeout = %s, esperado 2s", lockTimeout)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	writeTestConfig(t, "store = ")
+	if _, err := loadConfig(); err == nil {
+		t.Error("Se esperaba error con una configuración inválida")
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dueLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+	"domingo":   time.Sunday,
+	"lunes":     time.Monday,
+	"martes":    time.Tuesday,
+	"miercoles": time.Wednesday,
+	"miércoles": time.Wednesday,
+	"jueves":    time.Thursday,
+	"viernes":   time.Friday,
+	"sabado":    time.Saturday,
+	"sábado":    time.Saturday,
+}
+
+var relativeDue = regexp.MustCompile(`^(?:in|en) (\d+) (hours?|horas?|days?|d[ií]as?|weeks?|semanas?|months?|mes|meses)$`)
+
+// endOfDay devuelve el último segundo del día de t en su zona horaria; las
+// fechas sin hora vencen al terminar el día, no al empezar.
+func endOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 23, 59, 59, 0, t.Location())
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func addDays(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+n, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// parseDue interpreta fechas ISO ("2026-03-01", "2026-03-01 17:00") y frases
+// como "tomorrow", "next friday" o "in 3 days" (también "mañana", "viernes",
+// "en 3 días") relativas a now, en la zona horaria de now.
+func parseDue(s string, now time.Time) (time.Time, error) {
+	in := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	if d, err := time.ParseInLocation("2006-01-02", in, now.Location()); err == nil {
+		return endOfDay(d), nil
+	}
+	for _, layout := range dueLayouts {
+		if d, err := time.ParseInLocation(layout, strings.ToUpper(in), now.Location()); err == nil {
+			return d, nil
+		}
+	}
+	switch in {
+	case "today", "hoy":
+		return endOfDay(now), nil
+	case "tomorrow", "mañana", "manana":
+		return endOfDay(addDays(now, 1)), nil
+	case "yesterday", "ayer":
+		return endOfDay(addDays(now, -1)), nil
+	case "next week", "la próxima semana", "la proxima semana":
+		return endOfDay(addDays(now, 7)), nil
+	}
+	name := in
+	for _, prefix := range []string{"next ", "this ", "el ", "próximo ", "proximo "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	if wd, ok := weekdays[name]; ok {
+		diff := (int(wd) - int(now.Weekday()) + 7) % 7
+		if diff == 0 {
+			diff = 7
+		}
+		return endOfDay(addDays(now, diff)), nil
+	}
+	if m := relativeDue.FindStringSubmatch(in); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch unit := m[2]; {
+		case strings.HasPrefix(unit, "hour"), strings.HasPrefix(unit, "hora"):
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case strings.HasPrefix(unit, "week"), strings.HasPrefix(unit, "semana"):
+			return endOfDay(addDays(now, 7*n)), nil
+		case strings.HasPrefix(unit, "month"), strings.HasPrefix(unit, "mes"):
+			// AddDate pasaría del 31 de enero al 3 de marzo; como las
+			// repeticiones mensuales, se queda en el último día del mes.
+			return endOfDay(addMonthsClamped(now, n, now.Day())), nil
+		default:
+			return endOfDay(addDays(now, n)), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("fecha no reconocida: %q (usa AAAA-MM-DD, today, tomorrow, next friday, in 3 days...)", s)
+}
+
+func isOverdue(t Task, now time.Time) bool {
+	return t.Due != nil && t.Status != DONE && t.Due.Before(now)
+}
+
+// formatDue muestra la fecha en hora local, así que un vencimiento guardado
+// en otra zona (p. ej. UTC) sigue mostrándose como día si termina el día local.
+func formatDue(d time.Time) string {
+	d = d.Local()
+	if d.Hour() == 23 && d.Minute() == 59 && d.Second() == 59 {
+		return d.Format("2006-01-02")
+	}
+	return d.Format("2006-01-02 15:04")
+}
+
+const (
+	agendaOverdue = iota
+	agendaToday
+	agendaThisWeek
+	agendaLater
+)
+
+var agendaTitles = []string{"Vencidas", "Hoy", "Esta semana", "Más adelante"}
+
+// agendaBucket clasifica una fecha de vencimiento; la semana termina el domingo.
+func agendaBucket(due, now time.Time) int {
+	local := due.In(now.Location())
+	today := startOfDay(now)
+	switch {
+	case local.Before(now):
+		return agendaOverdue
+	case local.Before(addDays(today, 1)):
+		return agendaToday
+	}
+	daysToSunday := (7 - int(now.Weekday())) % 7
+	if local.Before(addDays(today, daysToSunday+1)) {
+		return agendaThisWeek
+	}
+	return agendaLater
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	// Miércoles 2026-03-04 10:30 en horario local.
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.Local)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 23, 59, 59, 0, time.Local) }
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2026-03-10", day(2026, 3, 10)},
+		{"2026-03-10 17:00", time.Date(2026, 3, 10, 17, 0, 0, 0, time.Local)},
+		{"2026-03-10T08:15", time.Date(2026, 3, 10, 8, 15, 0, 0, time.Local)},
+		{"today", day(2026, 3, 4)},
+		{"Tomorrow", day(2026, 3, 5)},
+		{"mañana", day(2026, 3, 5)},
+		{"next friday", day(2026, 3, 6)},
+		{"friday", day(2026, 3, 6)},
+		{"next wednesday", day(2026, 3, 11)},
+		{"viernes", day(2026, 3, 6)},
+		{"in 3 days", day(2026, 3, 7)},
+		{"en 2 semanas", day(2026, 3, 18)},
+		{"in 1 month", day(2026, 4, 4)},
+		{"in 2 hours", now.Add(2 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseDue(tt.input, now)
+		if err != nil {
+			t.Errorf("parseDue(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("parseDue(%q) = %v, esperado %v", tt.input, got, tt.expected)
+		}
+	}
+
+	// A final de mes, "in N months" se queda en el último día del mes destino.
+	endOfMonth := time.Date(2026, 1, 31, 10, 30, 0, 0, time.Local)
+	for input, want := range map[string]time.Time{
+		"in 1 month":   day(2026, 2, 28),
+		"in 3 months":  day(2026, 4, 30),
+		"en 2 meses":   day(2026, 3, 31),
+		"in 25 months": day(2028, 2, 29),
+	} {
+		if got, err := parseDue(input, endOfMonth); err != nil || !got.Equal(want) {
+			t.Errorf("parseDue(%q) el 31 de enero = %v, %v; esperado %v", input, got, err, want)
+		}
+	}
+
+	if _, err := parseDue("algún día", now); err == nil {
+		t.Error("Se esperaba error para una fecha no reconocida")
+	}
+}
+
+func TestParseDueAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skip("zona horaria no disponible:", err)
+	}
+	// El 29 de marzo de 2026 Madrid pasa al horario de verano.
+	now := time.Date(2026, 3, 28, 12, 0, 0, 0, loc)
+	got, err := parseDue("in 2 days", now)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if expected := time.Date(2026, 3, 30, 23, 59, 59, 0, loc); !got.Equal(expected) {
+		t.Errorf("parseDue = %v, esperado %v", got, expected)
+	}
+}
+
+func TestAgendaBucket(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		due      time.Time
+		expected int
+	}{
+		{time.Date(2026, 3, 3, 23, 59, 59, 0, time.Local), agendaOverdue},
+		{time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local), agendaOverdue},
+		{time.Date(2026, 3, 4, 23, 59, 59, 0, time.Local), agendaToday},
+		{time.Date(2026, 3, 8, 23, 59, 59, 0, time.Local), agendaThisWeek},
+		{time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local), agendaLater},
+	}
+	for _, tt := range tests {
+		if b := agendaBucket(tt.due, now); b != tt.expected {
+			t.Errorf("agendaBucket(%v) = %s, esperado %s", tt.due, agendaTitles[b], agendaTitles[tt.expected])
+		}
+	}
+}
+
+func TestIsOverdue(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	task := NewTask(1, "Vencida", "")
+	task.Due = &past
+	if !isOverdue(task, now) {
+		t.Error("Una tarea pendiente con fecha pasada debe estar vencida")
+	}
+	task.Status = DONE
+	if isOverdue(task, now) {
+		t.Error("Una tarea DONE nunca está vencida")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pendingBlockers devuelve las tareas de las que depende id que aún no están
+// en DONE. Las dependencias hacia tareas eliminadas se ignoran.
+func pendingBlockers(tasks []Task, id int) []int {
+	byID := map[int]Task{}
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	var pending []int
+	for _, b := range byID[id].BlockedBy {
+		if blocker, ok := byID[b]; ok && blocker.Status != DONE {
+			pending = append(pending, b)
+		}
+	}
+	return pending
+}
+
+// validateDependency comprueba que id pueda depender de blocker: ambas deben
+// existir y blocker no puede depender, directa o indirectamente, de id.
+func validateDependency(tasks []Task, id, blocker int) error {
+	if id == blocker {
+		return fmt.Errorf("una tarea no puede depender de sí misma")
+	}
+	deps := map[int][]int{}
+	for _, t := range tasks {
+		deps[t.ID] = t.BlockedBy
+	}
+	if _, ok := deps[blocker]; !ok {
+		return fmt.Errorf("la tarea %d no existe", blocker)
+	}
+	if path := dependencyPath(deps, blocker, id); path != nil {
+		return fmt.Errorf("se formaría un ciclo: %d -> %s", id, joinIDs(path, " -> "))
+	}
+	return nil
+}
+
+// dependencyPath busca un camino from -> ... -> to siguiendo BlockedBy.
+func dependencyPath(deps map[int][]int, from, to int) []int {
+	seen := map[int]bool{}
+	var walk func(n int) []int
+	walk = func(n int) []int {
+		if n == to {
+			return []int{n}
+		}
+		if seen[n] {
+			return nil
+		}
+		seen[n] = true
+		for _, next := range deps[n] {
+			if path := walk(next); path != nil {
+				return append([]int{n}, path...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+func addBlockers(blockedBy []int, ids ...int) []int {
+	for _, id := range ids {
+		if !containsID(blockedBy, id) {
+			blockedBy = append(blockedBy, id)
+		}
+	}
+	sort.Ints(blockedBy)
+	return blockedBy
+}
+
+func removeBlockers(blockedBy []int, ids ...int) []int {
+	var out []int
+	for _, b := range blockedBy {
+		if !containsID(ids, b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// dropReferences quita de t las dependencias y los enlaces hacia ids, las
+// tareas que se van a eliminar, e indica si había alguno.
+func dropReferences(t *Task, ids []int) bool {
+	changed := false
+	for _, id := range ids {
+		if containsID(t.BlockedBy, id) {
+			t.BlockedBy = removeBlockers(t.BlockedBy, id)
+			changed = true
+		}
+		if n := len(t.Links); n > 0 {
+			if t.Links = removeLinks(t.Links, id, ""); len(t.Links) != n {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func containsID(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func joinIDs(ids []int, sep string) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, sep)
+}
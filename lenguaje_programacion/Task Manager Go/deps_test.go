@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func depsTestTasks() []Task {
+	tasks := []Task{
+		NewTask(1, "Diseño", ""),
+		NewTask(2, "Implementación", ""),
+		NewTask(3, "Pruebas", ""),
+		NewTask(4, "Suelta", ""),
+	}
+	tasks[1].BlockedBy = []int{1}
+	tasks[2].BlockedBy = []int{2}
+	return tasks
+}
+
+func TestValidateDependency(t *testing.T) {
+	tasks := depsTestTasks()
+
+	if err := validateDependency(tasks, 3, 4); err != nil {
+		t.Errorf("3 -> 4 debería ser válida: %v", err)
+	}
+	if err := validateDependency(tasks, 2, 2); err == nil {
+		t.Error("Se esperaba error al depender de sí misma")
+	}
+	if err := validateDependency(tasks, 2, 99); err == nil {
+		t.Error("Se esperaba error para una tarea inexistente")
+	}
+	err := validateDependency(tasks, 1, 3)
+	if err == nil {
+		t.Fatal("Se esperaba error de ciclo 1 -> 3 -> 2 -> 1")
+	}
+	if !strings.Contains(err.Error(), "1 -> 3 -> 2 -> 1") {
+		t.Errorf("Mensaje = %q, esperado el ciclo 1 -> 3 -> 2 -> 1", err)
+	}
+}
+
+func TestPendingBlockers(t *testing.T) {
+	tasks := depsTestTasks()
+
+	if got := pendingBlockers(tasks, 2); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Pendientes de 2 = %v, esperado [1]", got)
+	}
+	tasks[0].Status = DONE
+	if got := pendingBlockers(tasks, 2); len(got) != 0 {
+		t.Errorf("Pendientes de 2 con 1 terminada = %v, esperado ninguno", got)
+	}
+}
+
+func TestAddRemoveBlockers(t *testing.T) {
+	got := addBlockers([]int{3}, 1, 3, 2)
+	if joinIDs(got, ",") != "1,2,3" {
+		t.Errorf("addBlockers = %v, esperado [1 2 3]", got)
+	}
+	got = removeBlockers(got, 2, 5)
+	if joinIDs(got, ",") != "1,3" {
+		t.Errorf("removeBlockers = %v, esperado [1 3]", got)
+	}
+}
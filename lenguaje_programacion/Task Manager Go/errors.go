@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitValidation = 2
+	exitNotFound   = 3
+	exitInvalidID  = 4
+	exitStorage    = 5
+)
+
+type NotFoundError struct {
+	ID int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("tarea %d no encontrada", e.ID)
+}
+
+type InvalidIDError struct {
+	Value string
+}
+
+func (e *InvalidIDError) Error() string {
+	return fmt.Sprintf("ID inválido: %s", e.Value)
+}
+
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Msg
+}
+
+func validationErrorf(format string, args ...any) error {
+	return &ValidationError{Msg: fmt.Sprintf(format, args...)}
+}
+
+type StorageError struct {
+	Op  string
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+func storageError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StorageError{Op: op, Err: err}
+}
+
+func exitCode(err error) int {
+	var notFound *NotFoundError
+	var invalidID *InvalidIDError
+	var validation *ValidationError
+	var storage *StorageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &notFound):
+		return exitNotFound
+	case errors.As(err, &invalidID):
+		return exitInvalidID
+	case errors.As(err, &validation):
+		return exitValidation
+	case errors.As(err, &storage):
+		return exitStorage
+	default:
+		return exitError
+	}
+}
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, &InvalidIDError{Value: arg}
+	}
+	return id, nil
+}
+
+func getTask(s Store, id int) (Task, error) {
+	t, err := s.Get(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return Task{}, &NotFoundError{ID: id}
+	}
+	if err != nil {
+		return Task{}, storageError("cargando tarea", err)
+	}
+	return t, nil
+}
+
+func withStore(fn func(s Store) error) error {
+	s, err := openStore()
+	if err != nil {
+		return storageError("abriendo almacenamiento", err)
+	}
+	defer s.Close()
+	return fn(s)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"sin error", nil, exitOK},
+		{"no encontrada", &NotFoundError{ID: 3}, exitNotFound},
+		{"ID inválido", &InvalidIDError{Value: "x"}, exitInvalidID},
+		{"validación", validationErrorf("mal"), exitValidation},
+		{"almacenamiento", storageError("guardando", errors.New("disco lleno")), exitStorage},
+		{"envuelto", fmt.Errorf("contexto: %w", &NotFoundError{ID: 1}), exitNotFound},
+		{"genérico", errors.New("otro"), exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.err); code != tt.expected {
+				t.Errorf("exitCode() = %d, esperado %d", code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseID(t *testing.T) {
+	if id, err := parseID("12"); err != nil || id != 12 {
+		t.Errorf("parseID(12) = %d, %v", id, err)
+	}
+	for _, arg := range []string{"abc", "0", "-3"} {
+		var invalid *InvalidIDError
+		if _, err := parseID(arg); !errors.As(err, &invalid) {
+			t.Errorf("parseID(%q) = %v, esperado InvalidIDError", arg, err)
+		}
+	}
+}
+
+func TestCommandErrorsKeepStdoutClean(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	var err error
+	output := captureOutput(func() {
+		err = cmdStart.RunE(cmdStart, []string{"abc"})
+	})
+	if exitCode(err) != exitInvalidID || output != "" {
+		t.Errorf("start abc = (%v, %q)", err, output)
+	}
+
+	output = captureOutput(func() {
+		err = cmdRemove.RunE(cmdRemove, []string{"7"})
+	})
+	if exitCode(err) != exitNotFound || output != "" {
+		t.Errorf("rm 7 = (%v, %q)", err, output)
+	}
+}
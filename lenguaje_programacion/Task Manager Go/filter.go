@@ -0,0 +1,129 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// taskFilter agrupa los filtros comunes de list y graph.
+type taskFilter struct {
+	query TaskQuery
+	preds []func(Task) bool
+	ready bool
+	state string
+}
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("state", "s", "all", "Filtrar por estado: all|todo|inprogress|done")
+	cmd.Flags().StringP("priority", "p", "", "Filtrar por prioridad: low|medium|high|urgent")
+	cmd.Flags().Bool("overdue", false, "Solo tareas vencidas sin terminar")
+	cmd.Flags().String("due-before", "", "Solo tareas que vencen antes de la fecha indicada")
+	cmd.Flags().StringArray("tag", nil, "Solo tareas con todas estas etiquetas (repetible)")
+	cmd.Flags().StringArray("any-tag", nil, "Solo tareas con alguna de estas etiquetas (repetible)")
+	cmd.Flags().String("project", "", "Solo tareas del proyecto y sus subproyectos")
+	cmd.Flags().Bool("ready", false, "Solo tareas TODO sin dependencias pendientes")
+	cmd.Flags().StringP("where", "w", "", "Expresión de filtro, p. ej. 'status != DONE and tag:backend'")
+	cmd.Flags().StringP("query", "q", "", "Aplicar una consulta guardada con 'taskcli query save'")
+}
+
+func parseFilterFlags(cmd *cobra.Command, now time.Time) (taskFilter, error) {
+	state, _ := cmd.Flags().GetString("state")
+	priorityFilter, _ := cmd.Flags().GetString("priority")
+	overdue, _ := cmd.Flags().GetBool("overdue")
+	dueBefore, _ := cmd.Flags().GetString("due-before")
+	allTags, _ := cmd.Flags().GetStringArray("tag")
+	anyTags, _ := cmd.Flags().GetStringArray("any-tag")
+	projectFilter, _ := cmd.Flags().GetString("project")
+	ready, _ := cmd.Flags().GetBool("ready")
+	where, _ := cmd.Flags().GetString("where")
+	queryName, _ := cmd.Flags().GetString("query")
+
+	f := taskFilter{ready: ready, state: state}
+	switch state {
+	case "all":
+	case "todo":
+		f.query.Statuses = []Status{TODO}
+	case "inprogress":
+		f.query.Statuses = []Status{INPROGRESS}
+	case "done":
+		f.query.Statuses = []Status{DONE}
+	default:
+		return f, validationErrorf("estado inválido: %s (usa all|todo|inprogress|done)", state)
+	}
+	if priorityFilter != "" {
+		priority, err := parsePriority(priorityFilter)
+		if err != nil {
+			return f, &ValidationError{Msg: err.Error()}
+		}
+		f.preds = append(f.preds, func(t Task) bool { return t.Priority == priority })
+	}
+	if overdue {
+		f.preds = append(f.preds, func(t Task) bool { return isOverdue(t, now) })
+	}
+	if dueBefore != "" {
+		limit, err := parseDue(dueBefore, now)
+		if err != nil {
+			return f, &ValidationError{Msg: err.Error()}
+		}
+		f.preds = append(f.preds, func(t Task) bool { return t.Due != nil && !t.Due.After(limit) })
+	}
+	if len(allTags) > 0 {
+		f.preds = append(f.preds, func(t Task) bool { return t.HasAllTags(allTags) })
+	}
+	if len(anyTags) > 0 {
+		f.preds = append(f.preds, func(t Task) bool { return t.HasAnyTag(anyTags) })
+	}
+	if projectFilter != "" {
+		project, err := parseProject(projectFilter)
+		if err != nil {
+			return f, &ValidationError{Msg: err.Error()}
+		}
+		f.preds = append(f.preds, func(t Task) bool { return inProject(t.Project, project) })
+	}
+	if queryName != "" {
+		expr, err := savedQuery(queryName)
+		if err != nil {
+			return f, err
+		}
+		pred, err := parseQuery(expr, now)
+		if err != nil {
+			return f, validationErrorf("consulta guardada %q: %v", queryName, err)
+		}
+		f.preds = append(f.preds, pred)
+	}
+	if where != "" {
+		pred, err := parseQuery(where, now)
+		if err != nil {
+			return f, &ValidationError{Msg: err.Error()}
+		}
+		f.preds = append(f.preds, pred)
+	}
+	if ready {
+		f.query.Statuses = []Status{TODO}
+	}
+	return f, nil
+}
+
+// filtered indica si hay algún filtro además del estado.
+func (f taskFilter) filtered() bool {
+	return len(f.preds) > 0 || f.ready
+}
+
+func (f taskFilter) apply(s Store) ([]Task, error) {
+	q := f.query
+	preds := f.preds
+	if f.ready {
+		all, err := s.Load()
+		if err != nil {
+			return nil, storageError("cargando tareas", err)
+		}
+		preds = append(preds[:len(preds):len(preds)], func(t Task) bool { return len(pendingBlockers(all, t.ID)) == 0 })
+	}
+	q.Match = allOf(preds)
+	tasks, err := s.Query(q)
+	if err != nil {
+		return nil, storageError("cargando tareas", err)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	graphFormats   = "dot|mermaid"
+	graphGroupings = "project|status|none"
+)
+
+const (
+	edgeParent = "parent"
+	edgeBlocks = "blocks"
+)
+
+// graphEdge une dos tareas; Kind es edgeParent, edgeBlocks o el tipo de un Link.
+type graphEdge struct {
+	From, To int
+	Kind     string
+}
+
+var statusColors = map[Status]string{
+	TODO:       "#e0e0e0",
+	INPROGRESS: "#ffd966",
+	DONE:       "#93c47d",
+}
+
+// graphEdges devuelve las relaciones entre las tareas dadas; las que apuntan
+// a tareas fuera del conjunto se omiten.
+func graphEdges(tasks []Task) []graphEdge {
+	present := map[int]bool{}
+	for _, t := range tasks {
+		present[t.ID] = true
+	}
+	var edges []graphEdge
+	for _, t := range tasks {
+		if t.ParentID != 0 && present[t.ParentID] {
+			edges = append(edges, graphEdge{From: t.ParentID, To: t.ID, Kind: edgeParent})
+		}
+		for _, b := range t.BlockedBy {
+			if present[b] {
+				edges = append(edges, graphEdge{From: b, To: t.ID, Kind: edgeBlocks})
+			}
+		}
+		for _, l := range t.Links {
+			if present[l.ID] {
+				edges = append(edges, graphEdge{From: t.ID, To: l.ID, Kind: l.Kind})
+			}
+		}
+	}
+	return edges
+}
+
+func validateGraphOptions(format, groupBy string) error {
+	switch format {
+	case "dot", "mermaid":
+	default:
+		return fmt.Errorf("formato de grafo inválido: %s (usa %s)", format, graphFormats)
+	}
+	switch groupBy {
+	case "project", "status", "none":
+	default:
+		return fmt.Errorf("agrupación inválida: %s (usa %s)", groupBy, graphGroupings)
+	}
+	return nil
+}
+
+func graphGroup(t Task, groupBy string) string {
+	switch groupBy {
+	case "project":
+		return t.Project
+	case "status":
+		return t.Status.String()
+	}
+	return ""
+}
+
+// groupTasks reparte las tareas por grupo; la clave "" son las tareas sin grupo.
+func groupTasks(tasks []Task, groupBy string) ([]string, map[string][]Task) {
+	groups := map[string][]Task{}
+	var names []string
+	for _, t := range tasks {
+		g := graphGroup(t, groupBy)
+		if _, ok := groups[g]; !ok && g != "" {
+			names = append(names, g)
+		}
+		groups[g] = append(groups[g], t)
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+func nodeLabel(t Task) string {
+	return fmt.Sprintf("[%d] %s", t.ID, t.Title)
+}
+
+func writeGraph(w io.Writer, format, groupBy string, tasks []Task) error {
+	var b strings.Builder
+	if format == "mermaid" {
+		writeMermaid(&b, groupBy, tasks)
+	} else {
+		writeDOT(&b, groupBy, tasks)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func writeDOT(b *strings.Builder, groupBy string, tasks []Task) {
+	b.WriteString("digraph tareas {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\"];\n")
+	names, groups := groupTasks(tasks, groupBy)
+	node := func(indent string, t Task) {
+		fmt.Fprintf(b, "%st%d [label=%s, fillcolor=%s];\n", indent, t.ID, dotQuote(nodeLabel(t)), dotQuote(statusColors[t.Status]))
+	}
+	for i, name := range names {
+		fmt.Fprintf(b, "  subgraph cluster_%d {\n    label=%s;\n", i, dotQuote(name))
+		for _, t := range groups[name] {
+			node("    ", t)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, t := range groups[""] {
+		node("  ", t)
+	}
+	for _, e := range graphEdges(tasks) {
+		switch e.Kind {
+		case edgeParent:
+			fmt.Fprintf(b, "  t%d -> t%d;\n", e.From, e.To)
+		case edgeBlocks:
+			fmt.Fprintf(b, "  t%d -> t%d [style=bold, color=\"#cc0000\", label=\"bloquea\"];\n", e.From, e.To)
+		default:
+			fmt.Fprintf(b, "  t%d -> t%d [style=dashed, label=%s];\n", e.From, e.To, dotQuote(e.Kind))
+		}
+	}
+	b.WriteString("}\n")
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+func mermaidClass(s Status) string {
+	return strings.ToLower(strings.ReplaceAll(s.String(), "_", ""))
+}
+
+func writeMermaid(b *strings.Builder, groupBy string, tasks []Task) {
+	b.WriteString("flowchart LR\n")
+	names, groups := groupTasks(tasks, groupBy)
+	node := func(indent string, t Task) {
+		fmt.Fprintf(b, "%st%d[\"%s\"]:::%s\n", indent, t.ID, mermaidEscaper.Replace(nodeLabel(t)), mermaidClass(t.Status))
+	}
+	for i, name := range names {
+		fmt.Fprintf(b, "  subgraph g%d[\"%s\"]\n", i, mermaidEscaper.Replace(name))
+		for _, t := range groups[name] {
+			node("    ", t)
+		}
+		b.WriteString("  end\n")
+	}
+	for _, t := range groups[""] {
+		node("  ", t)
+	}
+	for _, e := range graphEdges(tasks) {
+		switch e.Kind {
+		case edgeParent:
+			fmt.Fprintf(b, "  t%d --> t%d\n", e.From, e.To)
+		case edgeBlocks:
+			fmt.Fprintf(b, "  t%d ==>|bloquea| t%d\n", e.From, e.To)
+		default:
+			fmt.Fprintf(b, "  t%d -.->|%s| t%d\n", e.From, mermaidEscaper.Replace(e.Kind), e.To)
+		}
+	}
+	for _, s := range []Status{TODO, INPROGRESS, DONE} {
+		fmt.Fprintf(b, "  classDef %s fill:%s\n", mermaidClass(s), statusColors[s])
+	}
+}
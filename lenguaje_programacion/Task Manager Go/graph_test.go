@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func graphTestTasks() []Task {
+	tasks := []Task{
+		NewTask(1, "Diseño", ""),
+		NewTask(2, "API \"v2\"", ""),
+		NewTask(3, "Pruebas", ""),
+		NewTask(4, "Documentación", ""),
+	}
+	tasks[0].Status = DONE
+	tasks[0].Project = "backend"
+	tasks[1].Project = "backend"
+	tasks[1].Status = INPROGRESS
+	tasks[1].ParentID = 1
+	tasks[2].BlockedBy = []int{2, 9}
+	tasks[3].Links = []Link{{Kind: "related", ID: 3}}
+	return tasks
+}
+
+func TestGraphEdges(t *testing.T) {
+	edges := graphEdges(graphTestTasks())
+	want := []graphEdge{
+		{From: 1, To: 2, Kind: edgeParent},
+		{From: 2, To: 3, Kind: edgeBlocks},
+		{From: 4, To: 3, Kind: "related"},
+	}
+	if len(edges) != len(want) {
+		t.Fatalf("Aristas = %v, esperadas %v", edges, want)
+	}
+	for i := range want {
+		if edges[i] != want[i] {
+			t.Errorf("Arista %d = %v, esperada %v", i, edges[i], want[i])
+		}
+	}
+}
+
+func TestWriteGraphDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeGraph(&buf, "dot", "project", graphTestTasks()); err != nil {
+		t.Fatalf("Error generando DOT: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"digraph tareas {",
+		"subgraph cluster_0 {\n    label=\"backend\";\n    t1 [label=\"[1] Diseño\", fillcolor=\"#93c47d\"];",
+		`t2 [label="[2] API \"v2\"", fillcolor="#ffd966"];`,
+		"  t3 [label=\"[3] Pruebas\", fillcolor=\"#e0e0e0\"];",
+		"t1 -> t2;",
+		`t2 -> t3 [style=bold, color="#cc0000", label="bloquea"];`,
+		`t4 -> t3 [style=dashed, label="related"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT sin %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteGraphMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeGraph(&buf, "mermaid", "status", graphTestTasks()); err != nil {
+		t.Fatalf("Error generando Mermaid: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart LR\n",
+		"subgraph g0[\"DONE\"]\n    t1[\"[1] Diseño\"]:::done\n  end",
+		`t2["[2] API #quot;v2#quot;"]:::inprogress`,
+		"t1 --> t2",
+		"t2 ==>|bloquea| t3",
+		"t4 -.->|related| t3",
+		"classDef todo fill:#e0e0e0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid sin %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestValidateGraphOptions(t *testing.T) {
+	if err := validateGraphOptions("png", "project"); err == nil {
+		t.Error("Se esperaba error para formato png")
+	}
+	if err := validateGraphOptions("dot", "tag"); err == nil {
+		t.Error("Se esperaba error para agrupación por tag")
+	}
+}
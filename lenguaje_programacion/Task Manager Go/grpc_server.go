@@ -0,0 +1,625 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/namoruso/taskcli/taskpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultGRPCAddr = "localhost:50051"
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// watchInterval es cada cuánto WatchTasks revisa el almacenamiento en busca
+// de cambios hechos fuera del servidor (por la CLI local, por ejemplo).
+var watchInterval = time.Second
+
+var statusToProto = map[Status]taskpb.Status{
+	TODO:       taskpb.Status_STATUS_TODO,
+	INPROGRESS: taskpb.Status_STATUS_IN_PROGRESS,
+	DONE:       taskpb.Status_STATUS_DONE,
+}
+
+var priorityToProto = map[Priority]taskpb.Priority{
+	LOW:    taskpb.Priority_PRIORITY_LOW,
+	MEDIUM: taskpb.Priority_PRIORITY_MEDIUM,
+	HIGH:   taskpb.Priority_PRIORITY_HIGH,
+	URGENT: taskpb.Priority_PRIORITY_URGENT,
+}
+
+// statusFromProto trata STATUS_UNSPECIFIED como TODO, el estado de una tarea nueva.
+func statusFromProto(s taskpb.Status) (Status, error) {
+	if s == taskpb.Status_STATUS_UNSPECIFIED {
+		return TODO, nil
+	}
+	for st, p := range statusToProto {
+		if p == s {
+			return st, nil
+		}
+	}
+	return TODO, fmt.Errorf("estado desconocido: %d", s)
+}
+
+func priorityFromProto(p taskpb.Priority) (Priority, error) {
+	if p == taskpb.Priority_PRIORITY_UNSPECIFIED {
+		return MEDIUM, nil
+	}
+	for pr, v := range priorityToProto {
+		if v == p {
+			return pr, nil
+		}
+	}
+	return MEDIUM, fmt.Errorf("prioridad desconocida: %d", p)
+}
+
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// timeFromProto devuelve la hora local, como la que guardan los demás
+// almacenamientos.
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime().Local()
+	return &t
+}
+
+func taskToProto(t Task) *taskpb.Task {
+	p := &taskpb.Task{
+		Id:          int64(t.ID),
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      statusToProto[t.Status],
+		Priority:    priorityToProto[t.Priority],
+		Due:         timeToProto(t.Due),
+		Tags:        t.Tags,
+		Project:     t.Project,
+		ParentId:    int64(t.ParentID),
+		Recurrence:  t.Recurrence,
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+		Etag:        taskETag(t),
+		CompletedAt: timeToProto(t.CompletedAt),
+		StartedAt:   timeToProto(t.StartedAt),
+	}
+	for _, id := range t.BlockedBy {
+		p.BlockedBy = append(p.BlockedBy, int64(id))
+	}
+	for _, l := range t.Links {
+		p.Links = append(p.Links, &taskpb.Link{Kind: l.Kind, Id: int64(l.ID)})
+	}
+	for _, e := range t.Worklog {
+		p.Worklog = append(p.Worklog, &taskpb.WorkEntry{Start: timestamppb.New(e.Start), End: timeToProto(e.End), Note: e.Note, Paused: e.Paused})
+	}
+	return p
+}
+
+func taskFromProto(p *taskpb.Task) (Task, error) {
+	if p == nil {
+		return Task{}, validationErrorf("falta la tarea")
+	}
+	st, err := statusFromProto(p.Status)
+	if err != nil {
+		return Task{}, &ValidationError{Msg: err.Error()}
+	}
+	pr, err := priorityFromProto(p.Priority)
+	if err != nil {
+		return Task{}, &ValidationError{Msg: err.Error()}
+	}
+	t := Task{
+		ID:          int(p.Id),
+		Title:       p.Title,
+		Description: p.Description,
+		Status:      st,
+		Priority:    pr,
+		Due:         timeFromProto(p.Due),
+		Tags:        p.Tags,
+		Project:     p.Project,
+		ParentID:    int(p.ParentId),
+		Recurrence:  p.Recurrence,
+		CompletedAt: timeFromProto(p.CompletedAt),
+		StartedAt:   timeFromProto(p.StartedAt),
+	}
+	if ts := timeFromProto(p.CreatedAt); ts != nil {
+		t.CreatedAt = *ts
+	}
+	if ts := timeFromProto(p.UpdatedAt); ts != nil {
+		t.UpdatedAt = *ts
+	}
+	for _, id := range p.BlockedBy {
+		t.BlockedBy = append(t.BlockedBy, int(id))
+	}
+	for _, l := range p.Links {
+		t.Links = append(t.Links, Link{Kind: l.Kind, ID: int(l.Id)})
+	}
+	for _, e := range p.Worklog {
+		start := timeFromProto(e.Start)
+		if start == nil {
+			return Task{}, validationErrorf("intervalo de trabajo sin inicio")
+		}
+		t.Worklog = append(t.Worklog, WorkEntry{Start: *start, End: timeFromProto(e.End), Note: e.Note, Paused: e.Paused})
+	}
+	return t, nil
+}
+
+// grpcStatus es el equivalente de httpStatus para gRPC.
+func grpcStatus(err error) error {
+	var notFound *NotFoundError
+	var invalidID *InvalidIDError
+	var validation *ValidationError
+	code := codes.Internal
+	switch {
+	case errors.As(err, &notFound):
+		code = codes.NotFound
+	case errors.As(err, &invalidID), errors.As(err, &validation):
+		code = codes.InvalidArgument
+	case errors.Is(err, errLockTimeout):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// grpcServer implementa TaskService sobre el almacenamiento local con las
+// mismas funciones que la CLI y la API REST. mu serializa las escrituras,
+// changed avisa a WatchTasks de los cambios hechos por el propio servidor y
+// done, al cerrarse, termina los WatchTasks abiertos.
+type grpcServer struct {
+	taskpb.UnimplementedTaskServiceServer
+
+	done      <-chan struct{}
+	mu        sync.Mutex
+	changedMu sync.Mutex
+	changed   chan struct{}
+}
+
+func newGRPCServer(done <-chan struct{}) *grpcServer {
+	return &grpcServer{done: done, changed: make(chan struct{})}
+}
+
+func (g *grpcServer) changes() <-chan struct{} {
+	g.changedMu.Lock()
+	defer g.changedMu.Unlock()
+	return g.changed
+}
+
+func (g *grpcServer) notify() {
+	g.changedMu.Lock()
+	defer g.changedMu.Unlock()
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// write ejecuta una modificación con el almacenamiento abierto y avisa a los
+// WatchTasks en curso.
+func (g *grpcServer) write(fn func(s Store) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	err := withStore(fn)
+	if err == nil {
+		g.notify()
+	}
+	return err
+}
+
+func checkETag(t Task, etag string) error {
+	if etag != "" && etag != taskETag(t) {
+		return status.Errorf(codes.Aborted, "la tarea %d ha cambiado desde que se leyó; vuelve a intentarlo", t.ID)
+	}
+	return nil
+}
+
+func protoStatuses(in []taskpb.Status) ([]Status, error) {
+	var out []Status
+	for _, s := range in {
+		st, err := statusFromProto(s)
+		if err != nil {
+			return nil, &ValidationError{Msg: err.Error()}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (g *grpcServer) CreateTask(ctx context.Context, req *taskpb.CreateTaskRequest) (*taskpb.Task, error) {
+	t, err := taskFromProto(req.Task)
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	now := timeNow()
+	t.ID, t.CreatedAt, t.UpdatedAt = 0, now, now
+	var created Task
+	err = g.write(func(s Store) error {
+		tasks, err := s.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		if err := validateTaskFields(tasks, &t); err != nil {
+			return err
+		}
+		created, err = addTask(io.Discard, s, t)
+		return err
+	})
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	return taskToProto(created), nil
+}
+
+func (g *grpcServer) GetTask(ctx context.Context, req *taskpb.GetTaskRequest) (*taskpb.Task, error) {
+	var t Task
+	err := withStore(func(s Store) error {
+		var err error
+		t, err = getTask(s, int(req.Id))
+		return err
+	})
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	return taskToProto(t), nil
+}
+
+// ListTasks pagina por id: el token es el id de la última tarea devuelta.
+func (g *grpcServer) ListTasks(ctx context.Context, req *taskpb.ListTasksRequest) (*taskpb.ListTasksResponse, error) {
+	statuses, err := protoStatuses(req.Statuses)
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	q := TaskQuery{Statuses: statuses}
+	if req.Where != "" {
+		pred, err := parseQuery(req.Where, timeNow())
+		if err != nil {
+			return nil, grpcStatus(&ValidationError{Msg: err.Error()})
+		}
+		q.Match = pred
+	}
+	after := 0
+	if req.PageToken != "" {
+		if after, err = strconv.Atoi(req.PageToken); err != nil || after < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "page_token inválido: %q", req.PageToken)
+		}
+	}
+	size := int(req.PageSize)
+	switch {
+	case size < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size no puede ser negativo")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	var tasks []Task
+	err = withStore(func(s Store) error {
+		tasks, err = s.Query(q)
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	sortTasks(tasks, "id")
+	start := sort.Search(len(tasks), func(i int) bool { return tasks[i].ID > after })
+	resp := &taskpb.ListTasksResponse{}
+	end := min(start+size, len(tasks))
+	for _, t := range tasks[start:end] {
+		resp.Tasks = append(resp.Tasks, taskToProto(t))
+	}
+	if end < len(tasks) {
+		resp.NextPageToken = strconv.Itoa(tasks[end-1].ID)
+	}
+	return resp, nil
+}
+
+// updateMaskFields son los campos que admite update_mask, en el orden del
+// mensaje Task; remoteStore los envía todos porque guarda la tarea completa.
+var updateMaskFields = []string{"title", "description", "status", "priority", "due", "tags", "project", "parent_id", "blocked_by", "links", "worklog", "recurrence"}
+
+// applyUpdateMask copia de in a t los campos de paths. Un update_mask vacío es
+// un error: en proto3 un campo sin enviar no se distingue de uno vacío, así
+// que copiarlos todos borraría el registro de tiempo o dejaría la tarea en
+// TODO sin que el cliente lo pidiera.
+func applyUpdateMask(t *Task, in Task, paths []string) error {
+	if len(paths) == 0 {
+		return validationErrorf("update_mask no puede estar vacío; indica los campos a modificar")
+	}
+	for _, path := range paths {
+		switch path {
+		case "title":
+			t.Title = in.Title
+		case "description":
+			t.Description = in.Description
+		case "status":
+			t.Status = in.Status
+		case "priority":
+			t.Priority = in.Priority
+		case "due":
+			t.Due = in.Due
+		case "tags":
+			t.Tags = in.Tags
+		case "project":
+			t.Project = in.Project
+		case "parent_id":
+			t.ParentID = in.ParentID
+		case "blocked_by":
+			t.BlockedBy = in.BlockedBy
+		case "links":
+			t.Links = in.Links
+		case "worklog":
+			t.Worklog = in.Worklog
+		case "recurrence":
+			t.Recurrence = in.Recurrence
+		default:
+			return validationErrorf("el campo %s no se puede modificar", path)
+		}
+	}
+	return nil
+}
+
+// UpdateTask guarda los campos y el cambio de status en la misma escritura. El
+// cambio de status tiene los efectos de start y done, como en patchTask: mueve
+// el temporizador y crea la siguiente repetición.
+func (g *grpcServer) UpdateTask(ctx context.Context, req *taskpb.UpdateTaskRequest) (*taskpb.Task, error) {
+	in, err := taskFromProto(req.Task)
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	paths := req.UpdateMask.GetPaths()
+	if slices.Contains(paths, "status") && req.Task.GetStatus() == taskpb.Status_STATUS_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "status no puede ser STATUS_UNSPECIFIED")
+	}
+	var updated Task
+	err = g.write(func(s Store) error {
+		now := timeNow()
+		var change *statusChange
+		var err error
+		updated, err = updateTask(s, in.ID, func(t *Task) error {
+			if err := checkETag(*t, req.Etag); err != nil {
+				return err
+			}
+			patched := *t
+			if err := applyUpdateMask(&patched, in, paths); err != nil {
+				return err
+			}
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			if err := validateTaskFields(tasks, &patched); err != nil {
+				return err
+			}
+			want := patched.Status
+			patched.Status = t.Status
+			*t = patched
+			t.UpdatedAt = now
+			if want != t.Status {
+				c := applyStatus(t, want, now)
+				change = &c
+			}
+			return nil
+		})
+		if err != nil || change == nil {
+			return err
+		}
+		updated, err = finishStatus(io.Discard, s, updated, *change, now)
+		return err
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, grpcStatus(err)
+	}
+	return taskToProto(updated), nil
+}
+
+func (g *grpcServer) DeleteTask(ctx context.Context, req *taskpb.DeleteTaskRequest) (*taskpb.DeleteTaskResponse, error) {
+	id := int(req.Id)
+	err := g.write(func(s Store) error {
+		return removeTaskChecked(io.Discard, s, id, func(t Task, children []int) error {
+			if err := checkETag(t, req.Etag); err != nil {
+				return err
+			}
+			if n := len(children); n > 0 && !req.Recursive {
+				return status.Errorf(codes.FailedPrecondition, "la tarea %d tiene %d subtareas; usa recursive para eliminarlas también", id, n)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, grpcStatus(err)
+	}
+	return &taskpb.DeleteTaskResponse{}, nil
+}
+
+// WatchTasks compara el almacenamiento con la última versión enviada cada
+// watchInterval, o en cuanto este servidor modifica una tarea.
+func (g *grpcServer) WatchTasks(req *taskpb.WatchTasksRequest, stream grpc.ServerStreamingServer[taskpb.TaskEvent]) error {
+	statuses, err := protoStatuses(req.Statuses)
+	if err != nil {
+		return grpcStatus(err)
+	}
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	var sent map[int]string
+	for {
+		changed := g.changes()
+		var tasks []Task
+		err := withStore(func(s Store) error {
+			tasks, err = s.Query(TaskQuery{Statuses: statuses})
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return grpcStatus(err)
+		}
+		sortTasks(tasks, "id")
+		events, current := taskEvents(sent, tasks, sent != nil || req.Initial)
+		for _, ev := range events {
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+		sent = current
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-g.done:
+			return nil
+		case <-ticker.C:
+		case <-changed:
+		}
+	}
+}
+
+// taskEvents devuelve los eventos que llevan de sent (id -> etag) a tasks y
+// el nuevo estado; con emit false solo calcula el estado.
+func taskEvents(sent map[int]string, tasks []Task, emit bool) ([]*taskpb.TaskEvent, map[int]string) {
+	current := make(map[int]string, len(tasks))
+	var events []*taskpb.TaskEvent
+	for _, t := range tasks {
+		etag := taskETag(t)
+		current[t.ID] = etag
+		prev, ok := sent[t.ID]
+		switch {
+		case !emit || prev == etag:
+		case !ok:
+			events = append(events, &taskpb.TaskEvent{Kind: taskpb.TaskEvent_KIND_CREATED, Task: taskToProto(t)})
+		default:
+			events = append(events, &taskpb.TaskEvent{Kind: taskpb.TaskEvent_KIND_UPDATED, Task: taskToProto(t)})
+		}
+	}
+	var deleted []int
+	for id := range sent {
+		if _, ok := current[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	sort.Ints(deleted)
+	for _, id := range deleted {
+		events = append(events, &taskpb.TaskEvent{Kind: taskpb.TaskEvent_KIND_DELETED, Task: &taskpb.Task{Id: int64(id)}})
+	}
+	return events, current
+}
+
+// grpcServerOptions prepara el TLS y el token de serve --grpc. Sin los dos solo
+// se admite escuchar en loopback: TaskService no tiene otra protección y
+// cualquiera que alcance el puerto podría leer, cambiar o borrar las tareas.
+func grpcServerOptions(addr, certFile, keyFile, token string) ([]grpc.ServerOption, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, validationErrorf("--tls-cert y --tls-key deben indicarse juntos")
+	}
+	if !loopbackAddr(addr) && (certFile == "" || token == "") {
+		return nil, validationErrorf("serve --grpc en %s necesita --tls-cert, --tls-key y --token; sin ellos solo puede escuchar en localhost", addr)
+	}
+	var opts []grpc.ServerOption
+	if certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return nil, validationErrorf("certificado TLS inválido: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	if token != "" {
+		opts = append(opts, grpcAuth(token)...)
+	}
+	return opts, nil
+}
+
+// grpcAuth exige en cada llamada, unaria o de streaming, la cabecera
+// authorization con el token del servidor.
+func grpcAuth(token string) []grpc.ServerOption {
+	want := []byte("Bearer " + token)
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, got := range md.Get("authorization") {
+			if subtle.ConstantTimeCompare([]byte(got), want) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "falta el token o no es válido")
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
+
+// loopbackAddr indica si host:puerto solo es accesible desde este equipo. Un
+// host vacío escucha en todas las interfaces y otros nombres pueden resolver a
+// cualquier dirección, así que no cuentan.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveGRPC atiende TaskService en ln hasta que ctx termina; entonces cierra
+// los WatchTasks abiertos y espera a las llamadas en curso.
+func serveGRPC(ctx context.Context, ln net.Listener, opts ...grpc.ServerOption) error {
+	srv := grpc.NewServer(opts...)
+	taskpb.RegisterTaskServiceServer(srv, newGRPCServer(ctx.Done()))
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+	return <-done
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/namoruso/taskcli/taskpb"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// startGRPC sirve TaskService sobre el almacenamiento de prueba y devuelve
+// su dirección; el servidor se detiene al terminar el test.
+func startGRPC(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveGRPC(ctx, ln, opts...) }()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("serveGRPC = %v, esperado nil tras el apagado", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("serveGRPC no terminó tras cancelar el contexto")
+		}
+	})
+	return ln.Addr().String()
+}
+
+func dialRemote(t *testing.T, addr string) *remoteStore {
+	t.Helper()
+	s, err := openRemoteStore(addr, "", "")
+	if err != nil {
+		t.Fatalf("openRemoteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestRemoteStoreCommands(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	s := dialRemote(t, startGRPC(t))
+
+	due := time.Date(2026, 5, 1, 23, 59, 59, 0, time.Local)
+	parent, err := addTask(io.Discard, s, Task{Title: "Lanzamiento", Priority: HIGH, Tags: []string{"#Web"}, Due: &due, Recurrence: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("addTask: %v", err)
+	}
+	if parent.ID != 1 || parent.Priority != HIGH || len(parent.Tags) != 1 || parent.Tags[0] != "web" || !parent.Due.Equal(due) {
+		t.Errorf("Creada = %+v", parent)
+	}
+	if _, err := addTask(io.Discard, s, Task{Title: "Notas", ParentID: 1}); err != nil {
+		t.Fatalf("addTask subtarea: %v", err)
+	}
+	var validation *ValidationError
+	if _, err := addTask(io.Discard, s, Task{Title: "x", ParentID: 9}); !errors.As(err, &validation) {
+		t.Errorf("addTask con padre inexistente = %v, esperado ValidationError", err)
+	}
+
+	if err := startTask(io.Discard, s, 1, false); err != nil {
+		t.Fatalf("startTask: %v", err)
+	}
+	got, err := getTask(s, 1)
+	if err != nil || got.Status != INPROGRESS || !got.timerRunning() {
+		t.Errorf("Tras start = %+v, %v", got, err)
+	}
+
+	// Completar una tarea recurrente crea la siguiente a través del servidor.
+	if err := setStatus(io.Discard, s, 1, DONE); err != nil {
+		t.Fatalf("setStatus: %v", err)
+	}
+	todo, err := s.Query(TaskQuery{Statuses: []Status{TODO}, Match: func(t Task) bool { return t.Recurrence != "" }})
+	if err != nil || len(todo) != 1 || todo[0].ID != 3 {
+		t.Errorf("Siguiente repetición = %+v, %v", todo, err)
+	}
+
+	if err := removeTask(io.Discard, s, 1, false); !errors.As(err, &validation) {
+		t.Errorf("removeTask con subtareas = %v, esperado ValidationError", err)
+	}
+	if err := removeTask(io.Discard, s, 1, true); err != nil {
+		t.Fatalf("removeTask recursivo: %v", err)
+	}
+	var notFound *NotFoundError
+	if _, err := getTask(s, 2); !errors.As(err, &notFound) {
+		t.Errorf("getTask de subtarea eliminada = %v, esperado NotFoundError", err)
+	}
+
+	// El servidor guarda en el almacenamiento local de siempre.
+	tasks, err := loadTasks()
+	if err != nil || len(tasks) != 1 || tasks[0].ID != 3 {
+		t.Errorf("Almacenamiento local = %+v, %v", tasks, err)
+	}
+}
+
+func TestRemoteStoreETagConflict(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	addr := startGRPC(t)
+	a, b := dialRemote(t, addr), dialRemote(t, addr)
+
+	if _, err := a.Insert(Task{Title: "Original"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	ta, _ := a.Get(1)
+	tb, _ := b.Get(1)
+	ta.Title = "Desde a"
+	if err := a.Update(ta); err != nil {
+		t.Fatalf("Update a: %v", err)
+	}
+	tb.Title = "Desde b"
+	var validation *ValidationError
+	if err := b.Update(tb); !errors.As(err, &validation) {
+		t.Errorf("Update con etag antiguo = %v, esperado ValidationError", err)
+	}
+	if err := b.Delete(1); !errors.As(err, &validation) {
+		t.Errorf("Delete con etag antiguo = %v, esperado ValidationError", err)
+	}
+
+	// Tras volver a leer, b ya puede modificarla.
+	tb, _ = b.Get(1)
+	tb.Title = "Desde b"
+	if err := b.Update(tb); err != nil {
+		t.Errorf("Update tras releer: %v", err)
+	}
+	if got, _ := a.Get(1); got.Title != "Desde b" {
+		t.Errorf("Título = %q, esperado %q", got.Title, "Desde b")
+	}
+	if err := a.Save(nil); err == nil {
+		t.Error("Save en remoto debería fallar")
+	}
+}
+
+func TestGRPCListTasksPagination(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	s := dialRemote(t, startGRPC(t))
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		if _, err := s.Insert(Task{Title: title}); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	if err := setStatus(io.Discard, s, 2, DONE); err != nil {
+		t.Fatalf("setStatus: %v", err)
+	}
+
+	ctx := context.Background()
+	req := &taskpb.ListTasksRequest{Statuses: []taskpb.Status{taskpb.Status_STATUS_TODO}, PageSize: 2}
+	var ids []int64
+	pages := 0
+	for {
+		resp, err := s.client.ListTasks(ctx, req)
+		if err != nil {
+			t.Fatalf("ListTasks: %v", err)
+		}
+		pages++
+		for _, p := range resp.Tasks {
+			ids = append(ids, p.Id)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+	if pages != 2 || len(ids) != 4 || ids[0] != 1 || ids[1] != 3 || ids[3] != 5 {
+		t.Errorf("Páginas = %d, ids = %v", pages, ids)
+	}
+
+	resp, err := s.client.ListTasks(ctx, &taskpb.ListTasksRequest{Where: "title = e"})
+	if err != nil || len(resp.Tasks) != 1 || resp.Tasks[0].Id != 5 {
+		t.Errorf("ListTasks con where = %v, %v", resp, err)
+	}
+	if _, err := s.client.ListTasks(ctx, &taskpb.ListTasksRequest{PageToken: "x"}); err == nil {
+		t.Error("Se esperaba error con un page_token inválido")
+	}
+}
+
+func TestGRPCWatchTasks(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = 20 * time.Millisecond
+	s := dialRemote(t, startGRPC(t))
+	if _, err := s.Insert(Task{Title: "Existente"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := s.client.WatchTasks(ctx, &taskpb.WatchTasksRequest{Statuses: []taskpb.Status{taskpb.Status_STATUS_TODO}, Initial: true})
+	if err != nil {
+		t.Fatalf("WatchTasks: %v", err)
+	}
+	next := func(kind taskpb.TaskEvent_Kind, id int64) {
+		t.Helper()
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if ev.Kind != kind || ev.Task.GetId() != id {
+			t.Errorf("Evento = %v %d, esperado %v %d", ev.Kind, ev.Task.GetId(), kind, id)
+		}
+	}
+	next(taskpb.TaskEvent_KIND_CREATED, 1)
+
+	if _, err := s.Insert(Task{Title: "Nueva"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	next(taskpb.TaskEvent_KIND_CREATED, 2)
+
+	// Los cambios hechos directamente sobre el almacenamiento también llegan.
+	err = withStore(func(local Store) error {
+		_, err := updateTask(local, 2, func(t *Task) error {
+			t.Title = "Renombrada"
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("updateTask local: %v", err)
+	}
+	next(taskpb.TaskEvent_KIND_UPDATED, 2)
+
+	// Salir del filtro de estado cuenta como eliminada.
+	if err := setStatus(io.Discard, s, 1, DONE); err != nil {
+		t.Fatalf("setStatus: %v", err)
+	}
+	next(taskpb.TaskEvent_KIND_DELETED, 1)
+}
+
+// writeTestCert crea un certificado autofirmado para 127.0.0.1 y devuelve las
+// rutas del certificado y de su clave.
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestGRPCServerOptions(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	tests := []struct {
+		addr, cert, key, token string
+		ok                     bool
+	}{
+		{"localhost:50051", "", "", "", true},
+		{"127.0.0.1:50051", "", "", "", true},
+		{"[::1]:50051", "", "", "secreto", true},
+		{":50051", "", "", "", false},
+		{"0.0.0.0:50051", "", "", "secreto", false},
+		{"tareas.example:50051", certFile, keyFile, "", false},
+		{"0.0.0.0:50051", certFile, keyFile, "secreto", true},
+		{"localhost:50051", certFile, "", "", false},
+		{"localhost:50051", keyFile, keyFile, "", false},
+	}
+	for _, tt := range tests {
+		_, err := grpcServerOptions(tt.addr, tt.cert, tt.key, tt.token)
+		if (err == nil) != tt.ok {
+			t.Errorf("grpcServerOptions(%s, cert %t, key %t, token %t) = %v", tt.addr, tt.cert != "", tt.key != "", tt.token != "", err)
+		}
+	}
+}
+
+func TestRemoteStoreTLSAndToken(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	certFile, keyFile := writeTestCert(t)
+	opts, err := grpcServerOptions("127.0.0.1:0", certFile, keyFile, "secreto")
+	if err != nil {
+		t.Fatalf("grpcServerOptions: %v", err)
+	}
+	addr := startGRPC(t, opts...)
+	open := func(caFile, token string) *remoteStore {
+		t.Helper()
+		s, err := openRemoteStore(addr, caFile, token)
+		if err != nil {
+			t.Fatalf("openRemoteStore: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+
+	s := open(certFile, "secreto")
+	if _, err := s.Insert(Task{Title: "Cifrada"}); err != nil {
+		t.Fatalf("Insert con TLS y token: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := s.client.WatchTasks(ctx, &taskpb.WatchTasksRequest{Initial: true})
+	if err != nil {
+		t.Fatalf("WatchTasks: %v", err)
+	}
+	if ev, err := stream.Recv(); err != nil || ev.Task.GetId() != 1 {
+		t.Errorf("WatchTasks con token = %v, %v", ev, err)
+	}
+
+	var validation *ValidationError
+	if _, err := open(certFile, "otro").Load(); !errors.As(err, &validation) {
+		t.Errorf("Load con token incorrecto = %v, esperado ValidationError", err)
+	}
+	if _, err := open(certFile, "").Load(); !errors.As(err, &validation) {
+		t.Errorf("Load sin token = %v, esperado ValidationError", err)
+	}
+	stream, err = open(certFile, "otro").client.WatchTasks(ctx, &taskpb.WatchTasksRequest{Initial: true})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if err == nil {
+		t.Error("WatchTasks con token incorrecto debería fallar")
+	}
+	if _, err := open("", "secreto").Load(); err == nil {
+		t.Error("Load sin TLS debería fallar con un servidor TLS")
+	}
+}
+
+func TestApplyConfigRemote(t *testing.T) {
+	defer func() {
+		storeBackend = defaultStoreBackend
+		remoteAddr, remoteCA, authToken = "", "", ""
+	}()
+	newFlags := func(args ...string) *pflag.FlagSet {
+		storeBackend, remoteAddr, remoteCA, authToken = defaultStoreBackend, "", "", ""
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.StringVar(&storeBackend, "store", defaultStoreBackend, "")
+		flags.StringVar(&remoteAddr, "remote", "", "")
+		flags.StringVar(&remoteCA, "remote-ca", "", "")
+		flags.StringVar(&authToken, "token", "", "")
+		flags.Parse(args)
+		return flags
+	}
+
+	writeTestConfig(t, "remote = \"tareas:50051\"\n")
+	if err := applyConfig(newFlags()); err != nil || storeBackend != remoteStoreBackend || remoteAddr != "tareas:50051" {
+		t.Errorf("Con remote en config.toml: store = %s, remote = %s, %v", storeBackend, remoteAddr, err)
+	}
+	if err := applyConfig(newFlags("--store", "sqlite")); err != nil || storeBackend != "sqlite" {
+		t.Errorf("--store debe tener prioridad sobre remote de config.toml: store = %s, %v", storeBackend, err)
+	}
+	if err := applyConfig(newFlags("--remote", "otra:1")); err != nil || storeBackend != remoteStoreBackend || remoteAddr != "otra:1" {
+		t.Errorf("Con --remote: store = %s, remote = %s, %v", storeBackend, remoteAddr, err)
+	}
+	if err := applyConfig(newFlags("--remote", "otra:1", "--store", "json")); err == nil {
+		t.Error("Se esperaba error al combinar --remote con --store json")
+	}
+	cfgPath := writeTestConfig(t, "remote = \"tareas:50051\"\nremote_ca = \"ca.pem\"\ntoken = \"de-config\"\n")
+	wantCA := filepath.Join(filepath.Dir(cfgPath), "ca.pem")
+	if err := applyConfig(newFlags()); err != nil || remoteCA != wantCA || authToken != "de-config" {
+		t.Errorf("Con remote_ca y token en config.toml: remote_ca = %s, token = %s, %v", remoteCA, authToken, err)
+	}
+	t.Setenv(tokenEnvVar, "de-entorno")
+	if err := applyConfig(newFlags()); err != nil || authToken != "de-entorno" {
+		t.Errorf("$%s debe tener prioridad sobre config.toml: token = %s, %v", tokenEnvVar, authToken, err)
+	}
+	if err := applyConfig(newFlags("--token", "de-flag")); err != nil || authToken != "de-flag" {
+		t.Errorf("--token debe tener prioridad: token = %s, %v", authToken, err)
+	}
+
+	storeBackend, remoteAddr = remoteStoreBackend, ""
+	if _, err := openStore(); err == nil {
+		t.Error("Se esperaba error con el almacenamiento remote sin dirección")
+	}
+}
+
+func TestGRPCUpdateTaskStatus(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	s := dialRemote(t, startGRPC(t))
+	due := endOfDay(time.Now().AddDate(0, 0, 1))
+	if _, err := s.Insert(Task{Title: "Semanal", Due: &due, Recurrence: "FREQ=WEEKLY"}); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	ctx := context.Background()
+	update := func(st taskpb.Status) *taskpb.Task {
+		t.Helper()
+		p, err := s.client.UpdateTask(ctx, &taskpb.UpdateTaskRequest{
+			Task:       &taskpb.Task{Id: 1, Status: st},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+		})
+		if err != nil {
+			t.Fatalf("UpdateTask %v: %v", st, err)
+		}
+		return p
+	}
+
+	// Sin update_mask, o con status sin indicar, la llamada no adivina qué
+	// campos cambiar.
+	for _, req := range []*taskpb.UpdateTaskRequest{
+		{Task: &taskpb.Task{Id: 1, Title: "Semanal"}},
+		{Task: &taskpb.Task{Id: 1}, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}}},
+	} {
+		if _, err := s.client.UpdateTask(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("UpdateTask(%v) = %v, esperado InvalidArgument", req, err)
+		}
+	}
+
+	// Un cliente que solo envía status obtiene el temporizador y la
+	// siguiente repetición, igual que con start y done.
+	if p := update(taskpb.Status_STATUS_IN_PROGRESS); len(p.Worklog) != 1 || p.Worklog[0].End != nil {
+		t.Errorf("Tras IN_PROGRESS, worklog = %v, esperado un intervalo abierto", p.Worklog)
+	}
+	if p := update(taskpb.Status_STATUS_DONE); len(p.Worklog) != 1 || p.Worklog[0].End == nil || p.CompletedAt == nil {
+		t.Errorf("Tras DONE, worklog = %v y completed_at = %v, esperados el intervalo cerrado y la hora", p.Worklog, p.CompletedAt)
+	}
+	tasks, err := loadTasks()
+	if err != nil || len(tasks) != 2 || tasks[1].Status != TODO || tasks[1].Recurrence != "FREQ=WEEKLY" {
+		t.Fatalf("Tareas = %+v, %v; esperada la siguiente repetición", tasks, err)
+	}
+	if !tasks[1].Due.Equal(due.AddDate(0, 0, 7)) {
+		t.Errorf("Siguiente vencimiento = %v, esperado %v", tasks[1].Due, due.AddDate(0, 0, 7))
+	}
+
+	// Reenviar DONE no crea otra repetición, y el cliente remote tampoco
+	// la duplica al completar con setStatus.
+	update(taskpb.Status_STATUS_DONE)
+	if err := setStatus(io.Discard, s, 2, DONE); err != nil {
+		t.Fatalf("setStatus: %v", err)
+	}
+	if tasks, _ := loadTasks(); len(tasks) != 3 {
+		t.Errorf("Tareas = %d, esperadas 3", len(tasks))
+	}
+}
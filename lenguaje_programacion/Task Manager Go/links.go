@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultLinkKind = "related"
+
+// Link es una relación genérica de una tarea con otra; Kind es libre
+// ("related", "duplicates", ...) y se guarda en minúsculas.
+type Link struct {
+	Kind string `json:"kind"`
+	ID   int    `json:"id"`
+}
+
+func (l Link) String() string {
+	return fmt.Sprintf("%s %d", l.Kind, l.ID)
+}
+
+func normalizeLinkKind(kind string) (string, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		return defaultLinkKind, nil
+	}
+	if strings.ContainsAny(kind, " \t\"|") {
+		return "", fmt.Errorf("tipo de enlace inválido: %q", kind)
+	}
+	return kind, nil
+}
+
+func hasLink(links []Link, l Link) bool {
+	for _, existing := range links {
+		if existing == l {
+			return true
+		}
+	}
+	return false
+}
+
+func addLink(links []Link, l Link) []Link {
+	if hasLink(links, l) {
+		return links
+	}
+	return append(links, l)
+}
+
+// removeLinks quita los enlaces hacia id; con kind vacío quita todos los tipos.
+func removeLinks(links []Link, id int, kind string) []Link {
+	var out []Link
+	for _, l := range links {
+		if l.ID == id && (kind == "" || l.Kind == kind) {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+func linksLabel(links []Link) string {
+	parts := make([]string, len(links))
+	for i, l := range links {
+		parts[i] = l.String()
+	}
+	return strings.Join(parts, ", ")
+}
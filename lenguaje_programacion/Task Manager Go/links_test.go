@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestLinkHelpers(t *testing.T) {
+	links := addLink(nil, Link{Kind: "related", ID: 2})
+	links = addLink(links, Link{Kind: "related", ID: 2})
+	links = addLink(links, Link{Kind: "duplicates", ID: 2})
+	links = addLink(links, Link{Kind: "related", ID: 3})
+	if len(links) != 3 {
+		t.Fatalf("Enlaces = %v, esperados 3 sin duplicados", links)
+	}
+	if got := removeLinks(links, 2, "duplicates"); len(got) != 2 || hasLink(got, Link{Kind: "duplicates", ID: 2}) {
+		t.Errorf("removeLinks por tipo = %v", got)
+	}
+	if got := removeLinks(links, 2, ""); len(got) != 1 || got[0].ID != 3 {
+		t.Errorf("removeLinks sin tipo = %v, esperado solo el enlace a 3", got)
+	}
+	if got := linksLabel(links); got != "related 2, duplicates 2, related 3" {
+		t.Errorf("linksLabel = %q", got)
+	}
+}
+
+func TestNormalizeLinkKind(t *testing.T) {
+	if kind, _ := normalizeLinkKind(" Duplicates "); kind != "duplicates" {
+		t.Errorf("Tipo = %q, esperado duplicates", kind)
+	}
+	if kind, _ := normalizeLinkKind(""); kind != defaultLinkKind {
+		t.Errorf("Tipo vacío = %q, esperado %s", kind, defaultLinkKind)
+	}
+	if _, err := normalizeLinkKind("a|b"); err == nil {
+		t.Error("Se esperaba error para un tipo con |")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const defaultLockTimeout = 5 * time.Second
+
+var lockTimeout = defaultLockTimeout
+
+var errLockTimeout = errors.New("no se pudo obtener el bloqueo")
+
+type fileLock struct {
+	f *os.File
+}
+
+func acquireLock(path string, timeout time.Duration) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			return &fileLock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w de %s tras %s: otro proceso de taskcli lo está usando (ajusta --lock-timeout)", errLockTimeout, path, timeout)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
+
+func (l *fileLock) Release() error {
+	if err := unlockFile(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
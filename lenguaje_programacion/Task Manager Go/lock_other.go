@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// En plataformas sin flock no hay bloqueo entre procesos; el renombrado
+// atómico de Save sigue evitando archivos truncados.
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json.lock")
+
+	l, err := acquireLock(path, time.Second)
+	if err != nil {
+		t.Fatalf("Error obteniendo bloqueo: %v", err)
+	}
+
+	if _, err := acquireLock(path, 50*time.Millisecond); !errors.Is(err, errLockTimeout) {
+		t.Errorf("Segundo bloqueo = %v, esperado errLockTimeout", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Error liberando bloqueo: %v", err)
+	}
+	l, err = acquireLock(path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Bloqueo tras liberar: %v", err)
+	}
+	l.Release()
+}
+
+func TestJSONStoreConcurrentInserts(t *testing.T) {
+	s := &jsonStore{path: filepath.Join(t.TempDir(), "tasks.json")}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Insert(NewTask(0, "Concurrente", "")); err != nil {
+				t.Errorf("Error insertando: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	tasks, err := s.Load()
+	if err != nil {
+		t.Fatalf("Error cargando: %v", err)
+	}
+	if len(tasks) != n {
+		t.Fatalf("Se esperaban %d tareas, obtenidas %d", n, len(tasks))
+	}
+	seen := map[int]bool{}
+	for _, task := range tasks {
+		if seen[task.ID] {
+			t.Errorf("ID duplicado: %d", task.ID)
+		}
+		seen[task.ID] = true
+	}
+}
+
+// Dos comandos que modifican la misma tarea a la vez no deben perder cambios.
+func TestStoreConcurrentModify(t *testing.T) {
+	dir := t.TempDir()
+	db, err := openSQLiteStore(filepath.Join(dir, "tasks.db"))
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer db.Close()
+	stores := map[string]Store{"json": &jsonStore{path: filepath.Join(dir, "tasks.json")}, "sqlite": db}
+	for name, s := range stores {
+		if _, err := s.Insert(NewTask(0, "Compartida", "")); err != nil {
+			t.Fatalf("%s: error insertando: %v", name, err)
+		}
+		const n = 10
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(tag string) {
+				defer wg.Done()
+				_, err := s.Modify(1, func(t *Task) error {
+					t.Tags = addTags(t.Tags, tag)
+					return nil
+				})
+				if err != nil {
+					t.Errorf("%s: error modificando: %v", name, err)
+				}
+			}(fmt.Sprintf("t%d", i))
+		}
+		wg.Wait()
+		got, err := s.Get(1)
+		if err != nil || len(got.Tags) != n {
+			t.Errorf("%s: etiquetas = %v (%v), esperadas %d", name, got.Tags, err, n)
+		}
+
+		_, err = s.Modify(1, func(t *Task) error {
+			t.Title = "Cambiada"
+			return errors.New("cancelada")
+		})
+		if got, _ := s.Get(1); err == nil || got.Title != "Compartida" {
+			t.Errorf("%s: un error de fn no debe guardar nada: %v, título %q", name, err, got.Title)
+		}
+		if _, err := s.Modify(99, func(t *Task) error { return nil }); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s: Modify inexistente = %v, esperado os.ErrNotExist", name, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -12,7 +13,23 @@ func main() {
 		Use:   "taskcli",
 		Short: "CLI sencillo para gestionar tareas (JSON en $HOME/.taskcli/tasks.json)",
 		Long:  "taskcli es un CLI para gestionar tareas; soporta add, list, view, start, done, edit, rm.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return applyConfig(cmd.Flags())
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &ValidationError{Msg: err.Error()}
+	})
+
+	rootCmd.PersistentFlags().StringVar(&dataFile, "file", "", "Archivo de tareas (por defecto $TASKCLI_HOME/tasks.json, config.toml o $HOME/.taskcli/tasks.json)")
+	rootCmd.PersistentFlags().StringVar(&storeBackend, "store", defaultStoreBackend, "Almacenamiento a usar: "+strings.Join(storeBackendNames(), "|"))
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", defaultOutputFormat, "Formato de salida de list y view: "+outputFormatsHelp)
+	rootCmd.PersistentFlags().StringVar(&remoteAddr, "remote", "", "Usar las tareas de un servidor 'taskcli serve --grpc' (host:puerto)")
+	rootCmd.PersistentFlags().StringVar(&remoteCA, "remote-ca", "", "Certificado de la autoridad que firma el de --remote (PEM)")
+	rootCmd.PersistentFlags().StringVar(&authToken, "token", "", "Token de --remote y serve --grpc (por defecto $"+tokenEnvVar+" o la clave token de config.toml)")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", defaultLockTimeout, "Tiempo máximo de espera por el bloqueo del almacenamiento")
 
 	rootCmd.AddCommand(cmdAdd)
 	rootCmd.AddCommand(cmdList)
@@ -21,10 +38,33 @@ func main() {
 	rootCmd.AddCommand(cmdDone)
 	rootCmd.AddCommand(cmdEdit)
 	rootCmd.AddCommand(cmdRemove)
+	rootCmd.AddCommand(cmdAgenda)
+	rootCmd.AddCommand(cmdTags)
+	rootCmd.AddCommand(cmdProjects)
+	rootCmd.AddCommand(cmdDepends)
+	rootCmd.AddCommand(cmdUndepend)
+	rootCmd.AddCommand(cmdLink)
+	rootCmd.AddCommand(cmdUnlink)
+	rootCmd.AddCommand(cmdGraph)
+	rootCmd.AddCommand(cmdQuery)
+	rootCmd.AddCommand(cmdSearch)
+	rootCmd.AddCommand(cmdPause)
+	rootCmd.AddCommand(cmdResume)
+	rootCmd.AddCommand(cmdStop)
+	rootCmd.AddCommand(cmdLog)
+	rootCmd.AddCommand(cmdReport)
+	rootCmd.AddCommand(cmdUI)
+	rootCmd.AddCommand(cmdBoard)
+	rootCmd.AddCommand(cmdServe)
+	rootCmd.AddCommand(cmdWeb)
+	rootCmd.AddCommand(cmdMigrate)
+	rootCmd.AddCommand(cmdRepair)
+	rootCmd.AddCommand(cmdInit)
+	rootCmd.AddCommand(cmdInfo)
 	rootCmd.AddCommand(cmdVersion)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println("Error:", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
 	}
 }
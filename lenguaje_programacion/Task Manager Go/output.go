@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultOutputFormat = "text"
+
+var outputFormat = defaultOutputFormat
+
+const outputFormatsHelp = "text|json|ndjson|yaml|csv|template=<plantilla Go>"
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case "text", "json", "ndjson", "yaml", "csv":
+		return nil
+	}
+	if tmpl, ok := strings.CutPrefix(format, "template="); ok {
+		_, err := template.New("output").Parse(tmpl)
+		return err
+	}
+	return fmt.Errorf("formato de salida inválido: %s (usa %s)", format, outputFormatsHelp)
+}
+
+// writeTasks emite las tareas en un formato para scripts. Todos los formatos
+// parten de la codificación JSON de Task, así que comparten nombres de campo y
+// la representación de Status. Con single se emite un objeto en vez de una lista.
+func writeTasks(w io.Writer, format string, tasks []Task, single bool) error {
+	if tmpl, ok := strings.CutPrefix(format, "template="); ok {
+		return writeTemplate(w, tmpl, tasks)
+	}
+	var v any = tasks
+	if single && len(tasks) == 1 {
+		v = tasks[0]
+	}
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, t := range tasks {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "yaml":
+		return writeYAML(w, v)
+	case "csv":
+		return writeCSV(w, tasks)
+	}
+	return validateOutputFormat(format)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// JSON es YAML válido: decodificarlo a un nodo conserva el orden de los campos.
+	var node yaml.Node
+	if err := yaml.Unmarshal(b, &node); err != nil {
+		return err
+	}
+	resetYAMLStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func resetYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
+
+func taskJSONFields() []string {
+	typ := reflect.TypeOf(Task{})
+	fields := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func writeCSV(w io.Writer, tasks []Task) error {
+	fields := taskJSONFields()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = csvValue(values[f])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvValue(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+func writeTemplate(w io.Writer, text string, tasks []Task) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, t); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
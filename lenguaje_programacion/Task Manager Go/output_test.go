@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func outputTestTasks() []Task {
+	created := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tasks := []Task{
+		{ID: 1, Title: "Primera", Description: "Con, coma", Status: TODO, CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Title: "Segunda", Status: DONE, CreatedAt: created, UpdatedAt: created},
+	}
+	return tasks
+}
+
+func TestWriteTasksJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTasks(&buf, "json", outputTestTasks(), false); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	var decoded []Task
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON inválido: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].Status != DONE {
+		t.Errorf("Decodificado = %+v", decoded)
+	}
+
+	buf.Reset()
+	writeTasks(&buf, "json", outputTestTasks()[:1], true)
+	var single Task
+	if err := json.Unmarshal(buf.Bytes(), &single); err != nil {
+		t.Fatalf("Se esperaba un objeto JSON: %v", err)
+	}
+}
+
+func TestWriteTasksNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	writeTasks(&buf, "ndjson", outputTestTasks(), false)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Se esperaban 2 líneas, obtenidas %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `"status":"TODO"`) {
+		t.Errorf("Línea inesperada: %s", lines[0])
+	}
+}
+
+func TestWriteTasksYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTasks(&buf, "yaml", outputTestTasks(), false); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "- id: 1\n  title: Primera\n") {
+		t.Errorf("YAML debe conservar el orden de los campos JSON:\n%s", out)
+	}
+	var decoded []map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("YAML inválido: %v", err)
+	}
+	if decoded[1]["status"] != "DONE" {
+		t.Errorf("status = %v, esperado DONE", decoded[1]["status"])
+	}
+}
+
+func TestWriteTasksCSV(t *testing.T) {
+	var buf bytes.Buffer
+	writeTasks(&buf, "csv", outputTestTasks(), false)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV inválido: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Se esperaban 3 filas, obtenidas %d", len(records))
+	}
+	if strings.Join(records[0][:4], ",") != "id,title,description,status" {
+		t.Errorf("Cabecera = %v", records[0])
+	}
+	if records[1][2] != "Con, coma" || records[2][3] != "DONE" {
+		t.Errorf("Filas = %v", records[1:])
+	}
+}
+
+func TestWriteTasksTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTasks(&buf, "template={{.ID}}:{{.Status}}", outputTestTasks(), false); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if buf.String() != "1:TODO\n2:DONE\n" {
+		t.Errorf("Salida = %q", buf.String())
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, f := range []string{"text", "json", "ndjson", "yaml", "csv", "template={{.Title}}"} {
+		if err := validateOutputFormat(f); err != nil {
+			t.Errorf("%s debería ser válido: %v", f, err)
+		}
+	}
+	for _, f := range []string{"xml", "template={{.Title"} {
+		if err := validateOutputFormat(f); err == nil {
+			t.Errorf("%s debería ser inválido", f)
+		}
+	}
+}
+
+func TestCmdListJSONOutput(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	saveTasks(outputTestTasks())
+	outputFormat = "json"
+	defer func() { outputFormat = defaultOutputFormat }()
+
+	cmdList.Flags().Set("state", "done")
+	output := captureOutput(func() {
+		cmdList.RunE(cmdList, []string{})
+	})
+
+	var decoded []Task
+	if err := json.Unmarshal([]byte(output), &decoded); err != nil {
+		t.Fatalf("JSON inválido: %v\n%s", err, output)
+	}
+	if len(decoded) != 1 || decoded[0].ID != 2 {
+		t.Errorf("Tareas = %+v, esperada solo la 2", decoded)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+func projectTasksFile(dir string) string {
+	return filepath.Join(dir, storeDirName, storeFileName)
+}
+
+// findProjectTasksFile busca .taskcli/tasks.json en dir y sus padres, como git
+// hace con .git.
+func findProjectTasksFile(dir string) (string, bool) {
+	for {
+		path := projectTasksFile(dir)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func initProject(dir string) (string, error) {
+	path := projectTasksFile(dir)
+	if _, err := os.Stat(path); err == nil {
+		return "", validationErrorf("ya existe una lista de tareas en %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, []byte("[]"), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func activeStorePath() (string, error) {
+	if storeBackend == "sqlite" {
+		return sqliteFilePath()
+	}
+	return tasksFilePath()
+}
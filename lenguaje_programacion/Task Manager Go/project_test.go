@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitAndFindProjectTasksFile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	os.MkdirAll(nested, 0o755)
+
+	if _, ok := findProjectTasksFile(nested); ok {
+		t.Fatal("No debería encontrarse una lista antes de init")
+	}
+
+	path, err := initProject(root)
+	if err != nil {
+		t.Fatalf("Error en init: %v", err)
+	}
+	if _, err := initProject(root); err == nil {
+		t.Error("Se esperaba error al repetir init")
+	}
+
+	found, ok := findProjectTasksFile(nested)
+	if !ok || found != path {
+		t.Errorf("Encontrado = %s (%v), esperado %s", found, ok, path)
+	}
+
+	tasks, err := (&jsonStore{path: path}).Load()
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("Lista inicial = %v (%v), esperada vacía", tasks, err)
+	}
+}
+
+func TestResolveTasksLocationPrefersProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(homeEnvVar, "")
+
+	root := t.TempDir()
+	path, _ := initProject(root)
+	nested := filepath.Join(root, "src")
+	os.MkdirAll(nested, 0o755)
+
+	wd, _ := os.Getwd()
+	os.Chdir(nested)
+	defer os.Chdir(wd)
+
+	loc, err := resolveTasksLocation()
+	if err != nil {
+		t.Fatalf("Error resolviendo: %v", err)
+	}
+	if loc.Source != "proyecto" {
+		t.Errorf("Origen = %s, esperado proyecto", loc.Source)
+	}
+	if resolved, _ := filepath.EvalSymlinks(loc.Path); resolved != mustEvalSymlinks(t, path) {
+		t.Errorf("Archivo = %s, esperado %s", loc.Path, path)
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("EvalSymlinks(%s): %v", path, err)
+	}
+	return resolved
+}
+
+// La lista global está en $HOME/.taskcli, así que la búsqueda de proyecto la
+// encuentra desde cualquier directorio bajo $HOME; no debe contar como
+// proyecto ni tapar la clave file de config.toml.
+func TestResolveTasksLocationIgnoresGlobalAsProject(t *testing.T) {
+	real := t.TempDir()
+	home := filepath.Join(t.TempDir(), "home")
+	if err := os.Symlink(real, home); err != nil {
+		t.Skipf("Symlink: %v", err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv(homeEnvVar, "")
+	global := filepath.Join(home, storeDirName, storeFileName)
+	os.MkdirAll(filepath.Dir(global), 0o755)
+	if err := (&jsonStore{path: global}).Save(nil); err != nil {
+		t.Fatalf("Error creando la lista global: %v", err)
+	}
+	nested := filepath.Join(real, "trabajo", "web")
+	os.MkdirAll(nested, 0o755)
+
+	wd, _ := os.Getwd()
+	os.Chdir(nested)
+	defer os.Chdir(wd)
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if loc, err := resolveTasksLocation(); err != nil || loc.Source != "global" || loc.Path != global {
+		t.Errorf("Sin config.toml = %+v (%v), esperado global %s", loc, err, global)
+	}
+
+	cfgFile := filepath.Join(t.TempDir(), "tareas.json")
+	writeTestConfig(t, "file = \""+cfgFile+"\"\n")
+	if loc, err := resolveTasksLocation(); err != nil || loc.Source != "config.toml" || loc.Path != cfgFile {
+		t.Errorf("Con file en config.toml = %+v (%v), esperado %s", loc, err, cfgFile)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+)
+
+var queryNamePattern = regexp.MustCompile(`^[\p{L}\d_.-]+$`)
+
+type savedQueriesFile struct {
+	Queries map[string]string `toml:"queries"`
+}
+
+// queriesFilePath guarda las consultas junto a config.toml para que sirvan en
+// todas las listas del usuario.
+func queriesFilePath() (string, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "queries.toml"), nil
+}
+
+func loadSavedQueries() (map[string]string, error) {
+	path, err := queriesFilePath()
+	if err != nil {
+		return nil, err
+	}
+	var f savedQueriesFile
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, validationErrorf("consultas guardadas inválidas en %s: %v", path, err)
+	}
+	if f.Queries == nil {
+		f.Queries = map[string]string{}
+	}
+	return f.Queries, nil
+}
+
+func saveSavedQueries(queries map[string]string) error {
+	path, err := queriesFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(savedQueriesFile{Queries: queries}); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644)
+}
+
+func validateQueryName(name string) error {
+	if !queryNamePattern.MatchString(name) {
+		return fmt.Errorf("nombre de consulta inválido: %q (usa letras, números, '.', '_' o '-')", name)
+	}
+	return nil
+}
+
+func savedQuery(name string) (string, error) {
+	queries, err := loadSavedQueries()
+	if err != nil {
+		return "", err
+	}
+	expr, ok := queries[name]
+	if !ok {
+		return "", validationErrorf("no existe la consulta guardada %q (ver taskcli query list)", name)
+	}
+	return expr, nil
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Lenguaje de consultas de --where:
+//
+//	expr        = or
+//	or          = and { ("or" | "||") and }
+//	and         = unary { ("and" | "&&") unary }
+//	unary       = ("not" | "!") unary | "(" expr ")" | comparación
+//	comparación = campo operador valor | campo ":" valor
+//
+// Los valores con espacios van entre comillas: due < "next friday".
+
+const queryFields = "id, title, desc, status, priority, created, updated, due, tag, project, parent"
+
+type QuerySyntaxError struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	col := utf8.RuneCountInString(e.Query[:e.Pos])
+	return fmt.Sprintf("consulta inválida en la posición %d: %s\n  %s\n  %s^", col+1, e.Msg, e.Query, strings.Repeat(" ", col))
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokColon
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "el final de la consulta"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+const querySpecial = `()=!<>:~"'&|`
+
+func lexQuery(q string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(q); {
+		r, size := utf8.DecodeRuneInString(q[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ':':
+			toks = append(toks, token{tokColon, ":", i})
+			i++
+		case r == '"' || r == '\'':
+			end := strings.IndexRune(q[i+1:], r)
+			if end < 0 {
+				return nil, &QuerySyntaxError{q, i, "comillas sin cerrar"}
+			}
+			toks = append(toks, token{tokString, q[i+1 : i+1+end], i})
+			i += end + 2
+		case strings.HasPrefix(q[i:], "&&"), strings.HasPrefix(q[i:], "||"):
+			toks = append(toks, token{tokWord, q[i : i+2], i})
+			i += 2
+		case strings.ContainsRune("=!<>~", r):
+			op, size := string(r), 1
+			if i+1 < len(q) && q[i+1] == '=' && r != '~' {
+				size = 2
+				if r != '=' {
+					op += "="
+				}
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i += size
+		case strings.ContainsRune(querySpecial, r):
+			return nil, &QuerySyntaxError{q, i, fmt.Sprintf("carácter inesperado %q", r)}
+		default:
+			start := i
+			for i < len(q) {
+				r, size := utf8.DecodeRuneInString(q[i:])
+				if unicode.IsSpace(r) || strings.ContainsRune(querySpecial, r) {
+					break
+				}
+				i += size
+			}
+			toks = append(toks, token{tokWord, q[start:i], start})
+		}
+	}
+	return append(toks, token{tokEOF, "", len(q)}), nil
+}
+
+type queryParser struct {
+	query string
+	toks  []token
+	pos   int
+	now   time.Time
+}
+
+// parseQuery compila una expresión de --where a un predicado sobre tareas;
+// las fechas relativas ("today", "in 3 days") se resuelven respecto a now.
+func parseQuery(q string, now time.Time) (func(Task) bool, error) {
+	toks, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{query: q, toks: toks, now: now}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "la consulta está vacía")
+	}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "se esperaba and, or o el final de la consulta y se encontró %s", t.describe())
+	}
+	return pred, nil
+}
+
+func (p *queryParser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) errorf(t token, format string, args ...any) error {
+	return &QuerySyntaxError{Query: p.query, Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) isKeyword(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (func(Task) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Task) bool { return l(t) || right(t) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (func(Task) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and", "&&") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(t Task) bool { return l(t) && right(t) }
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (func(Task) bool, error) {
+	if p.isKeyword("not") || (p.peek().kind == tokOp && p.peek().text == "!") {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(t Task) bool { return !inner(t) }, nil
+	}
+	if p.peek().kind == tokLParen {
+		open := p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(p.peek(), "falta cerrar el paréntesis abierto en la posición %d", open.pos+1)
+		}
+		p.next()
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (func(Task) bool, error) {
+	field := p.next()
+	if field.kind != tokWord || isQueryKeyword(field.text) {
+		return nil, p.errorf(field, "se esperaba un campo (%s) y se encontró %s", queryFields, field.describe())
+	}
+	op := p.next()
+	switch {
+	case op.kind == tokColon:
+		op.text = ":"
+	case op.kind == tokOp && op.text != "!":
+	default:
+		return nil, p.errorf(op, "se esperaba un operador (=, !=, <, <=, >, >=, ~ o :) después de %q y se encontró %s", field.text, op.describe())
+	}
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, p.errorf(value, "se esperaba un valor después de %s y se encontró %s", op.text, value.describe())
+	}
+	return p.compile(field, op, value)
+}
+
+func isQueryKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "&&", "||":
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) compile(field, op, value token) (func(Task) bool, error) {
+	v := value.text
+	// En los campos ordenables "campo:valor" equivale a "campo = valor".
+	if op.text == ":" && !isTextField(field.text) {
+		op.text = "="
+	}
+	unsupported := func() error {
+		return p.errorf(op, "el operador %s no se aplica al campo %s", op.text, field.text)
+	}
+	switch strings.ToLower(field.text) {
+	case "id", "parent":
+		var n int
+		if strings.EqualFold(v, "none") {
+			n = 0
+		} else {
+			var err error
+			if n, err = strconv.Atoi(v); err != nil {
+				return nil, p.errorf(value, "se esperaba un número y se encontró %s", value.describe())
+			}
+		}
+		if !isOrderOp(op.text) {
+			return nil, unsupported()
+		}
+		get := func(t Task) int { return t.ID }
+		if strings.EqualFold(field.text, "parent") {
+			get = func(t Task) int { return t.ParentID }
+		}
+		return func(t Task) bool { return compareOp(cmpInt(get(t), n), op.text) }, nil
+	case "status":
+		st, err := parseStatus(strings.ReplaceAll(strings.ToUpper(v), "INPROGRESS", "IN_PROGRESS"))
+		if err != nil {
+			return nil, p.errorf(value, "estado desconocido %s (usa todo, in_progress o done)", value.describe())
+		}
+		if !isOrderOp(op.text) {
+			return nil, unsupported()
+		}
+		return func(t Task) bool { return compareOp(cmpInt(int(t.Status), int(st)), op.text) }, nil
+	case "priority":
+		pr, err := parsePriority(v)
+		if err != nil {
+			return nil, p.errorf(value, "%v", err)
+		}
+		if !isOrderOp(op.text) {
+			return nil, unsupported()
+		}
+		return func(t Task) bool { return compareOp(cmpInt(int(t.Priority), int(pr)), op.text) }, nil
+	case "title", "desc", "description":
+		get := func(t Task) string { return t.Title }
+		if !strings.EqualFold(field.text, "title") {
+			get = func(t Task) string { return t.Description }
+		}
+		needle := strings.ToLower(v)
+		switch op.text {
+		case ":", "~":
+			return func(t Task) bool { return strings.Contains(strings.ToLower(get(t)), needle) }, nil
+		case "=":
+			return func(t Task) bool { return strings.EqualFold(get(t), v) }, nil
+		case "!=":
+			return func(t Task) bool { return !strings.EqualFold(get(t), v) }, nil
+		}
+		return nil, unsupported()
+	case "tag", "tags":
+		switch op.text {
+		case ":", "=":
+			return func(t Task) bool { return containsTag(t.Tags, v) }, nil
+		case "!=":
+			return func(t Task) bool { return !containsTag(t.Tags, v) }, nil
+		}
+		return nil, unsupported()
+	case "project":
+		project := v
+		if strings.EqualFold(v, "none") {
+			project = ""
+		}
+		switch op.text {
+		case ":":
+			return func(t Task) bool { return inProject(t.Project, project) }, nil
+		case "=":
+			return func(t Task) bool { return t.Project == project }, nil
+		case "!=":
+			return func(t Task) bool { return t.Project != project }, nil
+		}
+		return nil, unsupported()
+	case "created", "updated", "due":
+		return p.compileDate(field, op, value)
+	}
+	return nil, p.errorf(field, "campo desconocido %q (usa %s)", field.text, queryFields)
+}
+
+// compileDate compara por día cuando el valor no lleva hora ("2026-01-01",
+// "today") y por instante en el resto de casos.
+func (p *queryParser) compileDate(field, op, value token) (func(Task) bool, error) {
+	if !isOrderOp(op.text) {
+		return nil, p.errorf(op, "el operador %s no se aplica al campo %s", op.text, field.text)
+	}
+	get := func(t Task) *time.Time { return &t.CreatedAt }
+	switch strings.ToLower(field.text) {
+	case "updated":
+		get = func(t Task) *time.Time { return &t.UpdatedAt }
+	case "due":
+		get = func(t Task) *time.Time { return t.Due }
+	}
+	if strings.EqualFold(value.text, "none") {
+		switch op.text {
+		case "=":
+			return func(t Task) bool { return get(t) == nil }, nil
+		case "!=":
+			return func(t Task) bool { return get(t) != nil }, nil
+		}
+		return nil, p.errorf(op, "con none solo se admiten = y !=")
+	}
+	when, err := parseDue(value.text, p.now)
+	if err != nil {
+		return nil, p.errorf(value, "%v", err)
+	}
+	byDay := when.Hour() == 23 && when.Minute() == 59 && when.Second() == 59
+	loc := p.now.Location()
+	return func(t Task) bool {
+		d := get(t)
+		if d == nil {
+			return false
+		}
+		if byDay {
+			return compareOp(startOfDay(d.In(loc)).Compare(startOfDay(when)), op.text)
+		}
+		return compareOp(d.Compare(when), op.text)
+	}, nil
+}
+
+func isTextField(field string) bool {
+	switch strings.ToLower(field) {
+	case "title", "desc", "description", "tag", "tags", "project":
+		return true
+	}
+	return false
+}
+
+func isOrderOp(op string) bool {
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareOp(c int, op string) bool {
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
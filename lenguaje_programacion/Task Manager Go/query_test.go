@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func queryTestTasks() []Task {
+	base := time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local)
+	due := time.Date(2026, 1, 20, 23, 59, 59, 0, time.Local)
+	tasks := []Task{
+		{ID: 1, Title: "Diseñar API", Status: DONE, Priority: HIGH, CreatedAt: base.AddDate(0, 0, -20), Tags: []string{"backend"}, Project: "backend.api"},
+		{ID: 2, Title: "Pantalla de login", Status: TODO, Priority: URGENT, CreatedAt: base, Tags: []string{"frontend"}},
+		{ID: 3, Title: "Migrar base de datos", Status: INPROGRESS, Priority: HIGH, CreatedAt: base, Tags: []string{"backend", "db"}, Project: "backend", Due: &due},
+		{ID: 4, Title: "Documentar", Status: TODO, Priority: LOW, CreatedAt: base.AddDate(0, 0, 1), ParentID: 3},
+	}
+	return tasks
+}
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"status != DONE and priority >= high and tag:backend and created > 2026-01-01", "3"},
+		{"status = todo or status = in_progress", "2,3,4"},
+		{"status:inprogress", "3"},
+		{"not tag:backend", "2,4"},
+		{"(priority = urgent or priority = low) and id > 2", "4"},
+		{"tag:backend && ! project:backend.api", "3"},
+		{"project:backend", "1,3"},
+		{"project = backend", "3"},
+		{"project = none", "2,4"},
+		{"title ~ base", "3"},
+		{"title:\"pantalla de\"", "2"},
+		{"due < 'in 10 days'", "3"},
+		{"due = none", "1,2,4"},
+		{"created = 2026-01-10", "2,3"},
+		{"created >= 2026-01-10 and created <= 2026-01-10", "2,3"},
+		{"parent = 3", "4"},
+		{"status == done", "1"},
+		{"PRIORITY > medium AND Tag:db", "3"},
+	}
+	for _, tt := range tests {
+		pred, err := parseQuery(tt.query, now)
+		if err != nil {
+			t.Errorf("parseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		var ids []int
+		for _, task := range queryTestTasks() {
+			if pred(task) {
+				ids = append(ids, task.ID)
+			}
+		}
+		if got := joinIDs(ids, ","); got != tt.want {
+			t.Errorf("parseQuery(%q) = %s, esperado %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		query string
+		msg   string
+		col   int
+	}{
+		{"", "vacía", 1},
+		{"status", "se esperaba un operador", 7},
+		{"status = ", "se esperaba un valor", 10},
+		{"status = DONE and", "se esperaba un campo", 18},
+		{"color = red", "campo desconocido", 1},
+		{"priority = altísima", "prioridad desconocida", 12},
+		{"status = DONE priority = high", "se esperaba and, or", 15},
+		{"(status = DONE", "falta cerrar el paréntesis", 15},
+		{"title = \"sin cerrar", "comillas sin cerrar", 9},
+		{"tag > backend", "no se aplica", 5},
+		{"created > someday", "fecha no reconocida", 11},
+		{"id = uno", "se esperaba un número", 6},
+		{"status = DONE & tag:x", "carácter inesperado", 15},
+	}
+	for _, tt := range tests {
+		_, err := parseQuery(tt.query, now)
+		if err == nil {
+			t.Errorf("parseQuery(%q) debería fallar", tt.query)
+			continue
+		}
+		syntaxErr, ok := err.(*QuerySyntaxError)
+		if !ok {
+			t.Errorf("parseQuery(%q) = %T, esperado *QuerySyntaxError", tt.query, err)
+			continue
+		}
+		if !strings.Contains(syntaxErr.Msg, tt.msg) {
+			t.Errorf("parseQuery(%q) = %q, esperado que contenga %q", tt.query, syntaxErr.Msg, tt.msg)
+		}
+		if col := len([]rune(tt.query[:syntaxErr.Pos])) + 1; col != tt.col {
+			t.Errorf("parseQuery(%q) posición = %d, esperada %d", tt.query, col, tt.col)
+		}
+	}
+}
+
+func TestQuerySyntaxErrorCaret(t *testing.T) {
+	_, err := parseQuery("título = x", time.Now())
+	want := "consulta inválida en la posición 1: campo desconocido \"título\""
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("Error = %v, esperado prefijo %q", err, want)
+	}
+	_, err = parseQuery("status ! DONE", time.Now())
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 || lines[2] != "         ^" {
+		t.Errorf("Error = %q, esperado el ^ bajo el operador", err)
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const seriesLinkKind = "series"
+
+// Recurrence es el subconjunto de RRULE (RFC 5545) que admite taskcli:
+// FREQ, INTERVAL, BYDAY (semanal), BYMONTHDAY (mensual), COUNT y UNTIL, más
+// DTSTART como una parte más de la regla.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+	// Count son las repeticiones que quedan contando la actual; cada nueva
+	// instancia hereda la regla con Count-1.
+	Count int
+	Until *time.Time
+	// Start es la fecha de la primera instancia. Las reglas anuales y las
+	// mensuales sin BYMONTHDAY cuentan desde ella y no desde la instancia
+	// anterior, que pudo quedar en un día recortado (28 de febrero).
+	Start *time.Time
+}
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var friendlyFreqs = map[string]string{
+	"daily": "DAILY", "diaria": "DAILY", "diario": "DAILY",
+	"weekly": "WEEKLY", "semanal": "WEEKLY",
+	"monthly": "MONTHLY", "mensual": "MONTHLY",
+	"yearly": "YEARLY", "anual": "YEARLY",
+}
+
+// parseRecurrence acepta una RRULE ("FREQ=WEEKLY;BYDAY=MO,FR", con o sin el
+// prefijo "RRULE:") o la forma abreviada "weekly on mon,fri", "monthly on 15",
+// "every 2 weeks", "daily".
+func parseRecurrence(s string) (Recurrence, error) {
+	in := strings.TrimSpace(s)
+	if rule, ok := strings.CutPrefix(strings.ToUpper(in), "RRULE:"); ok || strings.Contains(rule, "FREQ=") {
+		return parseRRule(rule)
+	}
+	return parseFriendlyRecurrence(strings.ToLower(in))
+}
+
+func parseRRule(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return r, fmt.Errorf("regla de repetición inválida: %q", part)
+		}
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = value
+		case "INTERVAL":
+			r.Interval, err = positiveInt(key, value)
+		case "BYDAY":
+			r.ByDay, err = parseRRuleDays(strings.Split(value, ","))
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = strconv.Atoi(value)
+			if err != nil || r.ByMonthDay == 0 || r.ByMonthDay < -1 || r.ByMonthDay > 31 {
+				err = fmt.Errorf("BYMONTHDAY debe ser un día entre 1 y 31 o -1 (último día): %s", value)
+			}
+		case "COUNT":
+			r.Count, err = positiveInt(key, value)
+		case "UNTIL":
+			// Solo se usa la fecha; la hora de "20261231T235959Z" se ignora.
+			until, perr := time.ParseInLocation("20060102", value[:min(8, len(value))], time.Local)
+			if perr != nil {
+				err = fmt.Errorf("UNTIL debe tener la forma AAAAMMDD: %s", value)
+				break
+			}
+			until = endOfDay(until)
+			r.Until = &until
+		case "DTSTART":
+			start, perr := time.ParseInLocation("20060102", value[:min(8, len(value))], time.Local)
+			if perr != nil {
+				err = fmt.Errorf("DTSTART debe tener la forma AAAAMMDD: %s", value)
+				break
+			}
+			r.Start = &start
+		default:
+			err = fmt.Errorf("parte de RRULE no admitida: %s (usa FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL o DTSTART)", key)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+	return r, r.validate()
+}
+
+func parseFriendlyRecurrence(in string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	words := strings.Fields(in)
+	if len(words) >= 3 && (words[0] == "every" || words[0] == "cada") {
+		n, err := positiveInt("every", words[1])
+		if err != nil {
+			return r, err
+		}
+		r.Interval = n
+		unit := strings.TrimSuffix(strings.TrimSuffix(words[2], "s"), "e")
+		freqs := map[string]string{"day": "DAILY", "dia": "DAILY", "día": "DAILY", "week": "WEEKLY", "semana": "WEEKLY", "month": "MONTHLY", "mes": "MONTHLY", "year": "YEARLY", "año": "YEARLY"}
+		if r.Freq = freqs[unit]; r.Freq == "" {
+			return r, fmt.Errorf("unidad de repetición desconocida: %s", words[2])
+		}
+		words = words[3:]
+	} else if len(words) > 0 {
+		if r.Freq = friendlyFreqs[words[0]]; r.Freq == "" {
+			return r, fmt.Errorf("repetición no reconocida: %q (usa daily, weekly on mon,fri, monthly on 15, every 2 weeks o una RRULE)", in)
+		}
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		if words[0] != "on" && words[0] != "el" && words[0] != "los" {
+			return r, fmt.Errorf("repetición no reconocida: %q", in)
+		}
+		arg := strings.Join(words[1:], "")
+		switch r.Freq {
+		case "WEEKLY":
+			var names []string
+			for _, name := range strings.Split(arg, ",") {
+				wd, ok := weekdayByPrefix(name)
+				if !ok {
+					return r, fmt.Errorf("día de la semana desconocido: %s", name)
+				}
+				names = append(names, rruleDays[wd])
+			}
+			r.ByDay, _ = parseRRuleDays(names)
+		case "MONTHLY":
+			if arg == "last" || arg == "último" || arg == "ultimo" {
+				r.ByMonthDay = -1
+			} else if n, err := strconv.Atoi(strings.TrimPrefix(arg, "day")); err == nil && n >= 1 && n <= 31 {
+				r.ByMonthDay = n
+			} else {
+				return r, fmt.Errorf("día del mes inválido: %s", arg)
+			}
+		default:
+			return r, fmt.Errorf("\"on\" solo se admite con weekly o monthly")
+		}
+	}
+	return r, r.validate()
+}
+
+// weekdayByPrefix reconoce "mon", "monday", "lun", "lunes"...
+func weekdayByPrefix(name string) (time.Weekday, bool) {
+	if len(name) < 2 {
+		return 0, false
+	}
+	for full, wd := range weekdays {
+		if strings.HasPrefix(full, name) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+func parseRRuleDays(names []string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range names {
+		found := false
+		for wd, code := range rruleDays {
+			if strings.EqualFold(name, code) {
+				if !containsWeekday(days, time.Weekday(wd)) {
+					days = append(days, time.Weekday(wd))
+				}
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("día de BYDAY desconocido: %s (usa MO, TU, WE, TH, FR, SA o SU)", name)
+		}
+	}
+	return days, nil
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+func positiveInt(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s debe ser un entero positivo: %s", name, value)
+	}
+	return n, nil
+}
+
+func (r Recurrence) validate() error {
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return fmt.Errorf("falta FREQ en la regla de repetición")
+	default:
+		return fmt.Errorf("FREQ no admitida: %s (usa DAILY, WEEKLY, MONTHLY o YEARLY)", r.Freq)
+	}
+	if len(r.ByDay) > 0 && r.Freq != "WEEKLY" {
+		return fmt.Errorf("BYDAY solo se admite con FREQ=WEEKLY")
+	}
+	if r.ByMonthDay != 0 && r.Freq != "MONTHLY" {
+		return fmt.Errorf("BYMONTHDAY solo se admite con FREQ=MONTHLY")
+	}
+	return nil
+}
+
+// String devuelve la regla en formato RRULE, que es como se guarda en Task.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			codes[i] = rruleDays[wd]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Start != nil {
+		parts = append(parts, "DTSTART="+r.Start.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// next devuelve la primera repetición posterior a after, a la misma hora del
+// día. ok es false cuando la serie terminó por COUNT o UNTIL.
+func (r Recurrence) next(after time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+	var next time.Time
+	switch r.Freq {
+	case "DAILY":
+		next = addDays(after, r.Interval)
+	case "WEEKLY":
+		next = r.nextWeekly(after)
+	case "MONTHLY":
+		next = r.nextMonthly(after)
+	case "YEARLY":
+		from := r.anchor(after)
+		next = stepMonths(from, after, 12*r.Interval, from.Day())
+	}
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r Recurrence) nextWeekly(after time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return addDays(after, 7*r.Interval)
+	}
+	loc := after.Location()
+	week := periodStart(after, "week", loc)
+	for i := 1; ; i++ {
+		candidate := addDays(after, i)
+		// Ambos son lunes a medianoche; redondear absorbe la hora de más o de
+		// menos de los cambios de horario.
+		days := int(math.Round(periodStart(candidate, "week", loc).Sub(week).Hours() / 24))
+		weeks := days / 7
+		if weeks%r.Interval == 0 && containsWeekday(r.ByDay, candidate.Weekday()) {
+			return candidate
+		}
+	}
+}
+
+func (r Recurrence) nextMonthly(after time.Time) time.Time {
+	from := r.anchor(after)
+	day := r.ByMonthDay
+	if day == 0 {
+		day = from.Day()
+	}
+	return stepMonths(from, after, r.Interval, day)
+}
+
+// anchor devuelve DTSTART con la hora del día de after, o after si la regla
+// no tiene DTSTART.
+func (r Recurrence) anchor(after time.Time) time.Time {
+	if r.Start == nil {
+		return after
+	}
+	y, m, d := r.Start.Date()
+	return time.Date(y, m, d, after.Hour(), after.Minute(), after.Second(), after.Nanosecond(), after.Location())
+}
+
+// stepMonths devuelve la primera fecha posterior a after que cae cada step
+// meses a partir del mes de from, en el día day.
+func stepMonths(from, after time.Time, step, day int) time.Time {
+	for k := 0; ; k += step {
+		candidate := addMonthsClamped(from, k, day)
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+}
+
+// needsAnchor indica si la regla repite el día del mes de la primera
+// instancia y por tanto necesita DTSTART.
+func (r Recurrence) needsAnchor() bool {
+	return r.Freq == "YEARLY" || r.Freq == "MONTHLY" && r.ByMonthDay == 0
+}
+
+// addMonthsClamped suma n meses y se queda en el día day, o en el último día
+// del mes si no existe (31 en abril, 29 de febrero); -1 es siempre el último.
+func addMonthsClamped(t time.Time, n, day int) time.Time {
+	y, m, _ := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if day == -1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// first devuelve la primera repetición a partir de hoy, al final del día, para
+// las tareas recurrentes creadas sin --due.
+func (r Recurrence) first(now time.Time) time.Time {
+	if len(r.ByDay) == 0 && r.ByMonthDay == 0 {
+		return endOfDay(now)
+	}
+	r.Count = 0
+	due, _ := r.next(endOfDay(addDays(now, -1)))
+	return due
+}
+
+// nextOccurrence crea la siguiente instancia de una tarea recurrente al
+// completarla: misma información, nueva fecha y un enlace a la serie. Si la
+// tarea se completa con retraso, salta las fechas que ya pasaron para no crear
+// una instancia vencida; las saltadas cuentan para COUNT.
+func nextOccurrence(t Task, now time.Time) (Task, bool, error) {
+	rule, err := parseRecurrence(t.Recurrence)
+	if err != nil {
+		return Task{}, false, err
+	}
+	base := now
+	if t.Due != nil {
+		base = *t.Due
+	}
+	// La primera instancia fija DTSTART para el resto de la serie.
+	if rule.Start == nil && rule.needsAnchor() {
+		start := base
+		rule.Start = &start
+	}
+	due, ok := rule.next(base)
+	for ok && !due.After(now) {
+		if rule.Count > 0 {
+			rule.Count--
+		}
+		due, ok = rule.next(due)
+	}
+	if !ok {
+		return Task{}, false, nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	next := NewTask(0, t.Title, t.Description)
+	next.Priority = t.Priority
+	next.Tags = append([]string(nil), t.Tags...)
+	next.Project = t.Project
+	next.ParentID = t.ParentID
+	next.Due = &due
+	next.Recurrence = rule.String()
+	next.Links = []Link{{Kind: seriesLinkKind, ID: seriesID(t)}}
+	return next, true, nil
+}
+
+// seriesID es el ID de la primera tarea de la serie.
+func seriesID(t Task) int {
+	for _, l := range t.Links {
+		if l.Kind == seriesLinkKind {
+			return l.ID
+		}
+	}
+	return t.ID
+}
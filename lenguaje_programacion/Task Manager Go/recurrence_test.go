@@ -0,0 +1,181 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"daily", "FREQ=DAILY"},
+		{"Semanal", "FREQ=WEEKLY"},
+		{"every 2 weeks", "FREQ=WEEKLY;INTERVAL=2"},
+		{"weekly on mon, fri", "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{"monthly on 15", "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"monthly on last", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=3", "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=3"},
+		{"freq=daily;until=20261231", "FREQ=DAILY;UNTIL=20261231"},
+	}
+	for _, tt := range tests {
+		r, err := parseRecurrence(tt.in)
+		if err != nil {
+			t.Errorf("parseRecurrence(%q): %v", tt.in, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("parseRecurrence(%q) = %s, esperado %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "hourly", "every 0 days", "weekly on funday", "FREQ=DAILY;BYDAY=MO", "monthly on 32"} {
+		if _, err := parseRecurrence(in); err == nil {
+			t.Errorf("parseRecurrence(%q) debería fallar", in)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// 2026-10-16 es viernes.
+	fri := time.Date(2026, 10, 16, 23, 59, 59, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 23, 59, 59, 0, time.UTC) }
+	tests := []struct {
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{"daily", fri, day(2026, 10, 17)},
+		{"weekly on mon,fri", fri, day(2026, 10, 19)},
+		{"weekly on mon,fri", day(2026, 10, 19), day(2026, 10, 23)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", fri, day(2026, 10, 26)},
+		{"monthly on 31", day(2026, 1, 31), day(2026, 2, 28)},
+		{"monthly on 31", day(2026, 2, 28), day(2026, 3, 31)},
+		{"monthly on last", day(2026, 1, 31), day(2026, 2, 28)},
+		{"monthly on 15", fri, day(2026, 11, 15)},
+		{"yearly", day(2028, 2, 29), day(2029, 2, 28)},
+		{"FREQ=YEARLY;DTSTART=20280229", day(2029, 2, 28), day(2030, 2, 28)},
+		{"FREQ=YEARLY;DTSTART=20280229", day(2031, 2, 28), day(2032, 2, 29)},
+		{"FREQ=YEARLY;INTERVAL=2;DTSTART=20280229", day(2030, 2, 28), day(2032, 2, 29)},
+		{"FREQ=MONTHLY;DTSTART=20260131", day(2026, 2, 28), day(2026, 3, 31)},
+		{"FREQ=MONTHLY;INTERVAL=2;DTSTART=20260131", day(2026, 3, 31), day(2026, 5, 31)},
+	}
+	for _, tt := range tests {
+		r, _ := parseRecurrence(tt.rule)
+		got, ok := r.next(tt.after)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s tras %s = %v (%v), esperado %v", tt.rule, tt.after.Format("2006-01-02"), got, ok, tt.want)
+		}
+	}
+
+	r, _ := parseRecurrence("FREQ=DAILY;UNTIL=20261017")
+	if _, ok := r.next(day(2026, 10, 17)); ok {
+		t.Error("UNTIL superado debería terminar la serie")
+	}
+	r, _ = parseRecurrence("FREQ=DAILY;COUNT=1")
+	if _, ok := r.next(fri); ok {
+		t.Error("COUNT=1 debería terminar la serie")
+	}
+
+	// Sin --due, la primera repetición semanal es el primer día de la regla desde hoy.
+	r, _ = parseRecurrence("weekly on fri")
+	if got := r.first(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)); !got.Equal(fri) {
+		t.Errorf("first = %v, esperado %v", got, fri)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 10, 16, 23, 59, 59, 0, time.UTC)
+	task := NewTask(4, "Informe", "semanal")
+	task.Priority = HIGH
+	task.Tags = []string{"equipo"}
+	task.Project = "ops"
+	task.Due = &due
+	task.Recurrence = "FREQ=WEEKLY;BYDAY=FR;COUNT=2"
+	task.Status = DONE
+
+	next, ok, err := nextOccurrence(task, now)
+	if err != nil || !ok {
+		t.Fatalf("nextOccurrence = %v, %v", ok, err)
+	}
+	if next.Status != TODO || next.Priority != HIGH || next.Project != "ops" || len(next.Tags) != 1 {
+		t.Errorf("Siguiente = %+v", next)
+	}
+	if want := due.AddDate(0, 0, 7); !next.Due.Equal(want) {
+		t.Errorf("Due = %v, esperado %v", next.Due, want)
+	}
+	if next.Recurrence != "FREQ=WEEKLY;BYDAY=FR;COUNT=1" {
+		t.Errorf("Recurrence = %s, esperado COUNT=1", next.Recurrence)
+	}
+	if seriesID(next) != 4 {
+		t.Errorf("seriesID = %d, esperado 4", seriesID(next))
+	}
+
+	next.ID = 5
+	next.Links = append(next.Links, Link{Kind: "related", ID: 2})
+	if _, ok, _ := nextOccurrence(next, now); ok {
+		t.Error("La serie con COUNT=1 debería terminar")
+	}
+	next.Recurrence = "daily"
+	if after, _, _ := nextOccurrence(next, now); seriesID(after) != 4 {
+		t.Errorf("seriesID = %d, esperado 4 (la primera de la serie)", seriesID(after))
+	}
+}
+
+// Completar con retraso salta las fechas ya pasadas, que cuentan para COUNT.
+func TestNextOccurrenceOverdue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 9, 4, 23, 59, 59, 0, time.UTC)
+	task := NewTask(1, "Informe", "")
+	task.Due = &due
+	task.Recurrence = "FREQ=WEEKLY;BYDAY=FR;COUNT=10"
+
+	next, ok, err := nextOccurrence(task, now)
+	if err != nil || !ok {
+		t.Fatalf("nextOccurrence = %v, %v", ok, err)
+	}
+	if want := time.Date(2026, 10, 23, 23, 59, 59, 0, time.UTC); !next.Due.Equal(want) {
+		t.Errorf("Due = %v, esperado %v, el primer viernes sin vencer", next.Due, want)
+	}
+	if next.Recurrence != "FREQ=WEEKLY;BYDAY=FR;COUNT=3" {
+		t.Errorf("Recurrence = %s, esperado COUNT=3 tras saltar 6 instancias", next.Recurrence)
+	}
+
+	task.Recurrence = "FREQ=WEEKLY;BYDAY=FR;COUNT=7"
+	if _, ok, _ := nextOccurrence(task, now); ok {
+		t.Error("La serie debería terminar si las instancias restantes ya vencieron")
+	}
+}
+
+// Una serie anual que empieza el 29 de febrero vuelve a él en los bisiestos
+// en lugar de quedarse en el 28 tras el primer año.
+func TestNextOccurrenceAnchored(t *testing.T) {
+	now := time.Date(2028, 3, 1, 10, 0, 0, 0, time.Local)
+	due := time.Date(2028, 2, 29, 23, 59, 59, 0, time.Local)
+	task := NewTask(1, "Aniversario", "")
+	task.Due = &due
+	task.Recurrence = "FREQ=YEARLY"
+
+	var dues []string
+	for i := 0; i < 4; i++ {
+		next, ok, err := nextOccurrence(task, now)
+		if err != nil || !ok {
+			t.Fatalf("nextOccurrence %d = %v, %v", i, ok, err)
+		}
+		dues = append(dues, next.Due.Format("2006-01-02"))
+		task = next
+	}
+	if got, want := strings.Join(dues, ","), "2029-02-28,2030-02-28,2031-02-28,2032-02-29"; got != want {
+		t.Errorf("Vencimientos = %s, esperado %s", got, want)
+	}
+	if task.Recurrence != "FREQ=YEARLY;DTSTART=20280229" {
+		t.Errorf("Recurrence = %s, esperado con DTSTART", task.Recurrence)
+	}
+
+	// Las reglas que no recortan el día no necesitan DTSTART.
+	task.Recurrence = "weekly on fri"
+	if next, _, _ := nextOccurrence(task, now); strings.Contains(next.Recurrence, "DTSTART") {
+		t.Errorf("Recurrence semanal = %s, esperada sin DTSTART", next.Recurrence)
+	}
+}
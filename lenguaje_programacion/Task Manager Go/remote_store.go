@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/namoruso/taskcli/taskpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	remoteStoreBackend = "remote"
+	remoteCallTimeout  = 10 * time.Second
+)
+
+// remoteAddr es el servidor de 'taskcli serve --grpc' que usa el
+// almacenamiento remote (--remote o la clave remote de config.toml) y
+// remoteCA, la autoridad con la que se comprueba su certificado. authToken lo
+// comparten el cliente y serve --grpc.
+var (
+	remoteAddr string
+	remoteCA   string
+	authToken  string
+)
+
+func init() {
+	registerStore(remoteStoreBackend, func() (Store, error) {
+		if remoteAddr == "" {
+			return nil, validationErrorf("el almacenamiento remote necesita --remote host:puerto")
+		}
+		return openRemoteStore(remoteAddr, remoteCA, authToken)
+	})
+}
+
+// remoteStore guarda las tareas en un servidor gRPC. Recuerda el etag de cada
+// tarea leída y lo envía al modificarla, de modo que un cambio hecho por otro
+// cliente entre medias no se pisa.
+type remoteStore struct {
+	addr   string
+	conn   *grpc.ClientConn
+	client taskpb.TaskServiceClient
+	etags  map[int]string
+}
+
+// openRemoteStore se conecta con TLS salvo a un servidor en loopback sin
+// caFile, que es el único caso en que serve --grpc admite texto plano.
+func openRemoteStore(addr, caFile, token string) (*remoteStore, error) {
+	creds, err := remoteTransport(addr, caFile)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	if err != nil {
+		return nil, validationErrorf("servidor remoto inválido %s: %v", addr, err)
+	}
+	return &remoteStore{addr: addr, conn: conn, client: taskpb.NewTaskServiceClient(conn), etags: map[int]string{}}, nil
+}
+
+func remoteTransport(addr, caFile string) (credentials.TransportCredentials, error) {
+	switch {
+	case caFile != "":
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, validationErrorf("no se pudo leer --remote-ca: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, validationErrorf("%s no contiene certificados PEM", caFile)
+		}
+		return credentials.NewTLS(&tls.Config{RootCAs: pool}), nil
+	case loopbackAddr(addr):
+		return insecure.NewCredentials(), nil
+	}
+	return credentials.NewTLS(&tls.Config{}), nil
+}
+
+// tokenCredentials envía el token en la cabecera authorization de cada llamada.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity es false para poder usar el token con un servidor
+// en loopback sin TLS; con cualquier otra dirección remoteTransport ya usa TLS.
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+func (s *remoteStore) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), remoteCallTimeout)
+}
+
+// remoteError traduce los códigos gRPC a los errores que esperan los comandos.
+func (s *remoteStore) remoteError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return os.ErrNotExist
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.Aborted:
+		return &ValidationError{Msg: st.Message()}
+	case codes.Unauthenticated:
+		return validationErrorf("el servidor %s rechazó la conexión: %s (revisa --token)", s.addr, st.Message())
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("el servidor %s no responde: %s", s.addr, st.Message())
+	}
+	return errors.New(st.Message())
+}
+
+func (s *remoteStore) remember(p *taskpb.Task) (Task, error) {
+	t, err := taskFromProto(p)
+	if err != nil {
+		return Task{}, err
+	}
+	s.etags[t.ID] = p.Etag
+	return t, nil
+}
+
+func (s *remoteStore) list(statuses []Status) ([]Task, error) {
+	req := &taskpb.ListTasksRequest{PageSize: maxPageSize}
+	for _, st := range statuses {
+		req.Statuses = append(req.Statuses, statusToProto[st])
+	}
+	tasks := []Task{}
+	for {
+		ctx, cancel := s.ctx()
+		resp, err := s.client.ListTasks(ctx, req)
+		cancel()
+		if err != nil {
+			return nil, s.remoteError(err)
+		}
+		for _, p := range resp.Tasks {
+			t, err := s.remember(p)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, t)
+		}
+		if resp.NextPageToken == "" {
+			return tasks, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+func (s *remoteStore) Load() ([]Task, error) {
+	return s.list(nil)
+}
+
+func (s *remoteStore) Save(tasks []Task) error {
+	return validationErrorf("el almacenamiento remote no admite reemplazar todas las tareas; usa migrate con un almacenamiento local")
+}
+
+func (s *remoteStore) Get(id int) (Task, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	p, err := s.client.GetTask(ctx, &taskpb.GetTaskRequest{Id: int64(id)})
+	if err != nil {
+		return Task{}, s.remoteError(err)
+	}
+	return s.remember(p)
+}
+
+func (s *remoteStore) Insert(t Task) (Task, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	p, err := s.client.CreateTask(ctx, &taskpb.CreateTaskRequest{Task: taskToProto(t)})
+	if err != nil {
+		return Task{}, s.remoteError(err)
+	}
+	return s.remember(p)
+}
+
+func (s *remoteStore) Update(t Task) error {
+	_, err := s.update(t, s.etags[t.ID])
+	return err
+}
+
+func (s *remoteStore) update(t Task, etag string) (Task, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	req := &taskpb.UpdateTaskRequest{
+		Task:       taskToProto(t),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: updateMaskFields},
+		Etag:       etag,
+	}
+	p, err := s.client.UpdateTask(ctx, req)
+	if err != nil {
+		return Task{}, s.remoteError(err)
+	}
+	return s.remember(p)
+}
+
+// Modify envía el etag leído con Get, aunque fn vuelva a leer con Load: si
+// otro cliente cambió la tarea entre medias, el servidor rechaza la escritura
+// en lugar de pisar su cambio.
+func (s *remoteStore) Modify(id int, fn func(t *Task) error) (Task, error) {
+	t, err := s.Get(id)
+	if err != nil {
+		return Task{}, err
+	}
+	etag := s.etags[id]
+	if err := fn(&t); err != nil {
+		return Task{}, err
+	}
+	t.ID = id
+	return s.update(t, etag)
+}
+
+func (s *remoteStore) Delete(id int) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	_, err := s.client.DeleteTask(ctx, &taskpb.DeleteTaskRequest{Id: int64(id), Etag: s.etags[id]})
+	if err != nil {
+		return s.remoteError(err)
+	}
+	delete(s.etags, id)
+	return nil
+}
+
+// DeleteTree comprueba las subtareas con la lista del servidor y las borra con
+// una sola llamada recursiva; el etag evita borrar una tarea que otro cliente
+// acaba de cambiar.
+func (s *remoteStore) DeleteTree(id int, check func(t Task, children []int) error) ([]int, error) {
+	t, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	etag := s.etags[id]
+	tasks, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	children := descendantIDs(tasks, id)
+	if err := check(t, children); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+	req := &taskpb.DeleteTaskRequest{Id: int64(id), Recursive: len(children) > 0, Etag: etag}
+	if _, err := s.client.DeleteTask(ctx, req); err != nil {
+		return nil, s.remoteError(err)
+	}
+	delete(s.etags, id)
+	return children, nil
+}
+
+// appliesStatusChanges: UpdateTask ya crea la siguiente repetición al
+// completar una tarea recurrente.
+func (s *remoteStore) appliesStatusChanges() {}
+
+// Query filtra por estado en el servidor y el resto localmente.
+func (s *remoteStore) Query(q TaskQuery) ([]Task, error) {
+	tasks, err := s.list(q.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	out := []Task{}
+	for _, t := range tasks {
+		if q.matches(t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *remoteStore) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const reportGroupings = "day|week|project|status"
+
+// reportRow resume un periodo o grupo. LeadTime es la media, en horas, desde
+// la creación hasta DONE de las tareas completadas en él.
+type reportRow struct {
+	Period    string   `json:"period"`
+	Created   int      `json:"created"`
+	Started   int      `json:"started"`
+	Completed int      `json:"completed"`
+	LeadTime  *float64 `json:"lead_time_hours,omitempty"`
+
+	leadTotal time.Duration
+}
+
+func (r *reportRow) addLead(d time.Duration) {
+	r.leadTotal += d
+	h := r.leadTotal.Hours() / float64(r.Completed)
+	r.LeadTime = &h
+}
+
+// startedAt es la hora que guardó setStatus al pasar por primera vez a
+// IN_PROGRESS. Las tareas anteriores a StartedAt usan el primer intervalo del
+// registro de tiempo, que start abre en ese mismo momento.
+func startedAt(t Task) (time.Time, bool) {
+	if t.StartedAt != nil {
+		return *t.StartedAt, true
+	}
+	if len(t.Worklog) > 0 {
+		return t.Worklog[0].Start, true
+	}
+	return time.Time{}, false
+}
+
+// completedAt es la hora que guardó setStatus al pasar a DONE. Las tareas
+// completadas antes de que existiera CompletedAt usan UpdatedAt, que es
+// aproximado: cualquier edición posterior lo cambia.
+func completedAt(t Task) (time.Time, bool) {
+	if t.Status != DONE {
+		return time.Time{}, false
+	}
+	if t.CompletedAt != nil {
+		return *t.CompletedAt, true
+	}
+	return t.UpdatedAt, true
+}
+
+func validateReportGrouping(groupBy string) error {
+	switch groupBy {
+	case "day", "week", "project", "status":
+		return nil
+	}
+	return fmt.Errorf("agrupación inválida: %s (usa %s)", groupBy, reportGroupings)
+}
+
+// periodStart devuelve el inicio del día o de la semana (lunes) de t en loc.
+// Se calcula con fechas de calendario, no sumando 24 h, para que los días de
+// cambio de horario (23 o 25 h) caigan en su periodo.
+func periodStart(t time.Time, groupBy string, loc *time.Location) time.Time {
+	day := startOfDay(t.In(loc))
+	if groupBy == "week" {
+		return addDays(day, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+func nextPeriod(start time.Time, groupBy string) time.Time {
+	if groupBy == "week" {
+		return addDays(start, 7)
+	}
+	return addDays(start, 1)
+}
+
+func periodLabel(start time.Time, groupBy string) string {
+	if groupBy == "week" {
+		y, w := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	}
+	return start.Format("2006-01-02")
+}
+
+// buildReport agrupa los eventos entre el inicio del día de from y el final
+// del día de to, ambos en loc. Con day y week se incluyen los periodos vacíos.
+func buildReport(tasks []Task, from, to time.Time, groupBy string, loc *time.Location) []reportRow {
+	begin := startOfDay(from.In(loc))
+	end := addDays(startOfDay(to.In(loc)), 1)
+	rows := map[string]*reportRow{}
+	var order []string
+	row := func(key string) *reportRow {
+		if r, ok := rows[key]; ok {
+			return r
+		}
+		rows[key] = &reportRow{Period: key}
+		order = append(order, key)
+		return rows[key]
+	}
+	byTime := groupBy == "day" || groupBy == "week"
+	if byTime {
+		for p := periodStart(begin, groupBy, loc); p.Before(end); p = nextPeriod(p, groupBy) {
+			row(periodLabel(p, groupBy))
+		}
+	}
+	key := func(t Task, at time.Time) string {
+		switch groupBy {
+		case "project":
+			if t.Project == "" {
+				return "(sin proyecto)"
+			}
+			return t.Project
+		case "status":
+			return t.Status.String()
+		}
+		return periodLabel(periodStart(at, groupBy, loc), groupBy)
+	}
+	inRange := func(at time.Time) bool {
+		return !at.Before(begin) && at.Before(end)
+	}
+	for _, t := range tasks {
+		if inRange(t.CreatedAt) {
+			row(key(t, t.CreatedAt)).Created++
+		}
+		if at, ok := startedAt(t); ok && inRange(at) {
+			row(key(t, at)).Started++
+		}
+		if at, ok := completedAt(t); ok && inRange(at) {
+			r := row(key(t, at))
+			r.Completed++
+			r.addLead(at.Sub(t.CreatedAt))
+		}
+	}
+	if !byTime {
+		sort.Strings(order)
+	}
+	out := make([]reportRow, len(order))
+	for i, k := range order {
+		out[i] = *rows[k]
+	}
+	return out
+}
+
+func reportTotal(rows []reportRow) reportRow {
+	total := reportRow{Period: "Total"}
+	for _, r := range rows {
+		total.Created += r.Created
+		total.Started += r.Started
+		total.Completed += r.Completed
+		total.leadTotal += r.leadTotal
+	}
+	if total.Completed > 0 {
+		h := total.leadTotal.Hours() / float64(total.Completed)
+		total.LeadTime = &h
+	}
+	return total
+}
+
+// formatLeadTime muestra la media en días y horas: "2d 5h", "7h", "-".
+func formatLeadTime(hours *float64) string {
+	if hours == nil {
+		return "-"
+	}
+	h := int(*hours + 0.5)
+	if h >= 24 {
+		return fmt.Sprintf("%dd %dh", h/24, h%24)
+	}
+	return fmt.Sprintf("%dh", h)
+}
+
+func writeReport(w io.Writer, format string, rows []reportRow) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Periodo\tCreadas\tIniciadas\tCompletadas\tLead time medio")
+		for _, r := range append(rows, reportTotal(rows)) {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", r.Period, r.Created, r.Started, r.Completed, formatLeadTime(r.LeadTime))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"period", "created", "started", "completed", "lead_time_hours"})
+		for _, r := range rows {
+			lead := ""
+			if r.LeadTime != nil {
+				lead = strconv.FormatFloat(*r.LeadTime, 'f', 2, 64)
+			}
+			cw.Write([]string{r.Period, strconv.Itoa(r.Created), strconv.Itoa(r.Started), strconv.Itoa(r.Completed), lead})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		b, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "yaml":
+		return writeYAML(w, rows)
+	}
+	return fmt.Errorf("el informe admite los formatos text, csv, json o yaml, no %s", format)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// reportTask crea una tarea cambiada por última vez en updated; las
+// IN_PROGRESS empezaron y las DONE se completaron en ese momento.
+func reportTask(id int, status Status, created, updated time.Time) Task {
+	t := NewTask(id, "Tarea", "")
+	t.Status = status
+	t.CreatedAt = created
+	t.UpdatedAt = updated
+	switch status {
+	case INPROGRESS:
+		t.StartedAt = &updated
+	case DONE:
+		t.CompletedAt = &updated
+	}
+	return t
+}
+
+func TestBuildReportDST(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatalf("Error cargando zona horaria: %v", err)
+	}
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 3, day, hour, min, 0, 0, madrid) }
+	// El 29 de marzo de 2026 dura 23 horas en Madrid.
+	tasks := []Task{
+		reportTask(1, DONE, at(28, 10, 0), at(29, 23, 30)),
+		reportTask(2, TODO, at(29, 0, 15), at(29, 0, 15)),
+		reportTask(3, INPROGRESS, at(29, 23, 59), at(30, 0, 30)),
+		reportTask(4, DONE, at(27, 9, 0), at(31, 9, 0)),
+	}
+	rows := buildReport(tasks, at(28, 0, 0), at(30, 0, 0), "day", madrid)
+	want := []struct {
+		period                      string
+		created, started, completed int
+	}{
+		{"2026-03-28", 1, 0, 0},
+		{"2026-03-29", 2, 0, 1},
+		{"2026-03-30", 0, 1, 0},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Filas = %+v, esperadas %d", rows, len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.Period != w.period || r.Created != w.created || r.Started != w.started || r.Completed != w.completed {
+			t.Errorf("Fila %d = %+v, esperada %+v", i, r, w)
+		}
+	}
+	// 28 10:00 -> 29 23:30 son 36,5 h reales: el cambio de hora resta una.
+	if lead := rows[1].LeadTime; lead == nil || *lead != 36.5 {
+		t.Errorf("Lead time = %v, esperado 36.5", lead)
+	}
+}
+
+func TestBuildReportWeeks(t *testing.T) {
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	at := func(month, day int) time.Time { return time.Date(2026, time.Month(month), day, 12, 0, 0, 0, madrid) }
+	tasks := []Task{
+		reportTask(1, DONE, at(10, 19), at(10, 25)),
+		reportTask(2, TODO, at(10, 26), at(10, 26)),
+	}
+	// La semana 43 termina el domingo 25 de octubre, día del cambio a horario de
+	// invierno; la tarea 1 se creó antes de --from y solo cuenta como completada.
+	rows := buildReport(tasks, at(10, 21), at(10, 28), "week", madrid)
+	if len(rows) != 2 || rows[0].Period != "2026-W43" || rows[1].Period != "2026-W44" {
+		t.Fatalf("Filas = %+v, esperadas las semanas 43 y 44", rows)
+	}
+	if rows[0].Created != 0 || rows[0].Completed != 1 || rows[1].Created != 1 {
+		t.Errorf("Filas = %+v", rows)
+	}
+	if got := formatLeadTime(rows[0].LeadTime); got != "6d 1h" {
+		t.Errorf("Lead time = %s, esperado 6d 1h", got)
+	}
+}
+
+func TestBuildReportByStatus(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		reportTask(1, DONE, now, now),
+		reportTask(2, TODO, now, now),
+		reportTask(3, TODO, now, now),
+		reportTask(4, TODO, now.AddDate(0, 0, -90), now.AddDate(0, 0, -90)),
+	}
+	rows := buildReport(tasks, now.AddDate(0, 0, -7), now, "status", time.Local)
+	if len(rows) != 2 || rows[0].Period != "DONE" || rows[1].Period != "TODO" || rows[1].Created != 2 {
+		t.Errorf("Filas = %+v", rows)
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	lead := 12.0
+	rows := []reportRow{
+		{Period: "2026-03-28", Created: 2, Completed: 1, LeadTime: &lead},
+		{Period: "2026-03-29"},
+	}
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "csv", rows); err != nil {
+		t.Fatalf("Error escribiendo CSV: %v", err)
+	}
+	want := "period,created,started,completed,lead_time_hours\n2026-03-28,2,0,1,12.00\n2026-03-29,0,0,0,\n"
+	if buf.String() != want {
+		t.Errorf("CSV = %q, esperado %q", buf.String(), want)
+	}
+	if err := writeReport(&buf, "ndjson", rows); err == nil {
+		t.Error("Se esperaba error para ndjson")
+	}
+}
+
+// Editar una tarea ya completada cambia UpdatedAt pero no el día en que se
+// completó; las DONE sin CompletedAt se atribuyen al día de UpdatedAt.
+func TestBuildReportUsesCompletedAt(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	now := time.Now()
+	later := now.AddDate(0, 0, 2)
+
+	// Completada antes de que existiera CompletedAt: cuenta por UpdatedAt.
+	yesterday := now.AddDate(0, 0, -1)
+	legacy := reportTask(2, DONE, yesterday.AddDate(0, 0, -1), yesterday)
+	legacy.CompletedAt = nil
+	saveTasks([]Task{NewTask(1, "Informe", ""), legacy})
+	err := withStore(func(s Store) error {
+		if err := setStatus(io.Discard, s, 1, DONE); err != nil {
+			return err
+		}
+		_, err := updateTask(s, 1, func(t *Task) error {
+			t.Title = "Informe final"
+			t.UpdatedAt = later
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error actualizando: %v", err)
+	}
+	tasks, _ := loadTasks()
+	done := tasks[0].CompletedAt
+	if done == nil || done.Before(now) || !done.Before(later) {
+		t.Fatalf("CompletedAt = %v, esperado el momento de done", done)
+	}
+
+	rows := buildReport(tasks, now.AddDate(0, 0, -1), later, "day", time.Local)
+	completed := map[string]int{}
+	for _, r := range rows {
+		completed[r.Period] = r.Completed
+	}
+	before, today, after := yesterday.Format("2006-01-02"), now.Format("2006-01-02"), later.Format("2006-01-02")
+	if completed[before] != 1 || completed[today] != 1 || completed[after] != 0 {
+		t.Errorf("Completadas por día = %v, esperada una el %s y otra el %s", completed, before, today)
+	}
+
+	err = withStore(func(s Store) error { return setStatus(io.Discard, s, 1, TODO) })
+	if tasks, _ := loadTasks(); err != nil || tasks[0].CompletedAt != nil {
+		t.Errorf("Al reabrir, CompletedAt = %v (%v), esperado nil", tasks[0].CompletedAt, err)
+	}
+}
+
+// El día de inicio es el que guardó start, aunque la tarea se edite después o
+// vuelva a TODO, y sin depender del registro de tiempo.
+func TestBuildReportUsesStartedAt(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	now := time.Now()
+	later := now.AddDate(0, 0, 2)
+	saveTasks([]Task{NewTask(1, "Informe", "")})
+	err := withStore(func(s Store) error {
+		if err := setStatus(io.Discard, s, 1, INPROGRESS); err != nil {
+			return err
+		}
+		if err := setStatus(io.Discard, s, 1, TODO); err != nil {
+			return err
+		}
+		_, err := updateTask(s, 1, func(t *Task) error {
+			t.Worklog = nil
+			t.UpdatedAt = later
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Error actualizando: %v", err)
+	}
+	tasks, _ := loadTasks()
+	if start := tasks[0].StartedAt; start == nil || start.Before(now) || !start.Before(later) {
+		t.Fatalf("StartedAt = %v, esperado el momento de start", start)
+	}
+
+	started := map[string]int{}
+	for _, r := range buildReport(tasks, now, later, "day", time.Local) {
+		started[r.Period] = r.Started
+	}
+	if today := now.Format("2006-01-02"); started[today] != 1 {
+		t.Errorf("Iniciadas por día = %v, esperada una el %s", started, today)
+	}
+}
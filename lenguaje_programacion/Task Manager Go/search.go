@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	fieldTitle = iota
+	fieldDescription
+)
+
+// Una coincidencia en el título pesa más que en la descripción.
+var fieldWeights = []float64{fieldTitle: 3, fieldDescription: 1}
+
+type posting struct {
+	Field int
+	Pos   int
+}
+
+// postings agrupa las apariciones de un término por ID de tarea.
+type postings map[int][]posting
+
+// termIndex es un índice invertido de Title y Description.
+type termIndex interface {
+	lookup(term string, prefix bool) (postings, error)
+	docCount() (int, error)
+}
+
+// indexedStore lo implementan los almacenamientos que mantienen el índice al
+// guardar, hoy solo SQLite; con el resto, search construye uno en memoria a
+// partir de Load.
+type indexedStore interface {
+	SearchIndex() (termIndex, error)
+}
+
+// foldTerm normaliza un término para que "Canción", "cancion" y "CANCIÓN"
+// sean equivalentes: descompone los caracteres (NFD) y quita las marcas
+// diacríticas, así que también pliega los textos que ya llegan descompuestos,
+// como los nombres de archivo de macOS. El Transformer guarda estado, por eso
+// se crea uno en cada llamada.
+func foldTerm(s string) string {
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, strings.ToLower(s))
+	if err != nil {
+		return strings.ToLower(s)
+	}
+	return folded
+}
+
+type textToken struct {
+	term       string
+	start, end int
+}
+
+func tokenizeText(s string) []textToken {
+	var toks []textToken
+	start := -1
+	for i, r := range s {
+		// Las marcas combinantes de un texto en NFD forman parte de la palabra.
+		word := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+		if word && start < 0 {
+			start = i
+		}
+		if !word && start >= 0 {
+			toks = append(toks, textToken{foldTerm(s[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		toks = append(toks, textToken{foldTerm(s[start:]), start, len(s)})
+	}
+	return toks
+}
+
+// taskTerms devuelve las apariciones de cada término de la tarea.
+func taskTerms(t Task) map[string][]posting {
+	terms := map[string][]posting{}
+	for field, text := range []string{fieldTitle: t.Title, fieldDescription: t.Description} {
+		for pos, tok := range tokenizeText(text) {
+			terms[tok.term] = append(terms[tok.term], posting{Field: field, Pos: pos})
+		}
+	}
+	return terms
+}
+
+// memIndex es el índice en memoria que search construye para los
+// almacenamientos sin indexedStore.
+type memIndex struct {
+	Docs   int
+	Terms  map[string]postings
+	sorted []string
+}
+
+func buildIndex(tasks []Task) *memIndex {
+	ix := &memIndex{Docs: len(tasks), Terms: map[string]postings{}}
+	for _, t := range tasks {
+		for term, ps := range taskTerms(t) {
+			if ix.Terms[term] == nil {
+				ix.Terms[term] = postings{}
+			}
+			ix.Terms[term][t.ID] = ps
+		}
+	}
+	return ix
+}
+
+func (ix *memIndex) lookup(term string, prefix bool) (postings, error) {
+	if !prefix {
+		return ix.Terms[term], nil
+	}
+	if ix.sorted == nil {
+		ix.sorted = make([]string, 0, len(ix.Terms))
+		for t := range ix.Terms {
+			ix.sorted = append(ix.sorted, t)
+		}
+		sort.Strings(ix.sorted)
+	}
+	out := postings{}
+	for i := sort.SearchStrings(ix.sorted, term); i < len(ix.sorted) && strings.HasPrefix(ix.sorted[i], term); i++ {
+		for id, ps := range ix.Terms[ix.sorted[i]] {
+			out[id] = append(out[id], ps...)
+		}
+	}
+	return out, nil
+}
+
+func (ix *memIndex) docCount() (int, error) {
+	return ix.Docs, nil
+}
+
+// searchClause es una palabra ("api", "migra*" con Prefix) o una frase entre
+// comillas que debe aparecer con las palabras seguidas.
+type searchClause struct {
+	Words  []string
+	Prefix bool
+}
+
+func parseSearchQuery(q string) ([]searchClause, error) {
+	var clauses []searchClause
+	for rest := q; rest != ""; {
+		i := strings.IndexByte(rest, '"')
+		if i < 0 {
+			clauses = append(clauses, wordClauses(rest)...)
+			break
+		}
+		clauses = append(clauses, wordClauses(rest[:i])...)
+		end := strings.IndexByte(rest[i+1:], '"')
+		if end < 0 {
+			return nil, fmt.Errorf("comillas sin cerrar en la búsqueda: %s", q)
+		}
+		var words []string
+		for _, tok := range tokenizeText(rest[i+1 : i+1+end]) {
+			words = append(words, tok.term)
+		}
+		if len(words) > 0 {
+			clauses = append(clauses, searchClause{Words: words})
+		}
+		rest = rest[i+1+end+1:]
+	}
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("indica al menos un término de búsqueda")
+	}
+	return clauses, nil
+}
+
+func wordClauses(s string) []searchClause {
+	var clauses []searchClause
+	for _, field := range strings.Fields(s) {
+		prefix := strings.HasSuffix(field, "*")
+		toks := tokenizeText(field)
+		for i, tok := range toks {
+			clauses = append(clauses, searchClause{Words: []string{tok.term}, Prefix: prefix && i == len(toks)-1})
+		}
+	}
+	return clauses
+}
+
+type searchHit struct {
+	ID    int
+	Score float64
+}
+
+// searchTasks devuelve las tareas que contienen todas las cláusulas, de mayor
+// a menor puntuación (frecuencia ponderada por campo × idf).
+func searchTasks(ix termIndex, clauses []searchClause) ([]searchHit, error) {
+	n, err := ix.docCount()
+	if err != nil {
+		return nil, err
+	}
+	var scores map[int]float64
+	for _, c := range clauses {
+		matches, err := clauseMatches(ix, c)
+		if err != nil {
+			return nil, err
+		}
+		idf := math.Log(1 + float64(n)/float64(max(len(matches), 1)))
+		next := map[int]float64{}
+		for id, ps := range matches {
+			if scores != nil {
+				if _, ok := scores[id]; !ok {
+					continue
+				}
+			}
+			var tf float64
+			for _, p := range ps {
+				tf += fieldWeights[p.Field]
+			}
+			next[id] = scores[id] + tf*idf
+		}
+		scores = next
+		if len(scores) == 0 {
+			break
+		}
+	}
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, searchHit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits, nil
+}
+
+// clauseMatches devuelve, por tarea, dónde empieza cada aparición de la cláusula.
+func clauseMatches(ix termIndex, c searchClause) (postings, error) {
+	first, err := ix.lookup(c.Words[0], c.Prefix && len(c.Words) == 1)
+	if err != nil || len(c.Words) == 1 {
+		return first, err
+	}
+	rest := make([]map[int]map[posting]bool, len(c.Words)-1)
+	for i, w := range c.Words[1:] {
+		ps, err := ix.lookup(w, false)
+		if err != nil {
+			return nil, err
+		}
+		rest[i] = map[int]map[posting]bool{}
+		for id, list := range ps {
+			set := map[posting]bool{}
+			for _, p := range list {
+				set[p] = true
+			}
+			rest[i][id] = set
+		}
+	}
+	out := postings{}
+	for id, starts := range first {
+		for _, p := range starts {
+			ok := true
+			for i := range rest {
+				if !rest[i][id][posting{Field: p.Field, Pos: p.Pos + i + 1}] {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				out[id] = append(out[id], p)
+			}
+		}
+	}
+	return out, nil
+}
+
+// highlight marca en text las palabras que coinciden con alguna cláusula.
+func highlight(text string, clauses []searchClause, openMark, closeMark string) string {
+	var b strings.Builder
+	last := 0
+	for _, tok := range tokenizeText(text) {
+		if !matchesClauses(tok.term, clauses) {
+			continue
+		}
+		b.WriteString(text[last:tok.start])
+		b.WriteString(openMark + text[tok.start:tok.end] + closeMark)
+		last = tok.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func matchesClauses(term string, clauses []searchClause) bool {
+	for _, c := range clauses {
+		for _, w := range c.Words {
+			if term == w || (c.Prefix && strings.HasPrefix(term, w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// snippet recorta text alrededor de la primera coincidencia.
+func snippet(text string, clauses []searchClause, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	start := 0
+	for _, tok := range tokenizeText(text) {
+		if matchesClauses(tok.term, clauses) {
+			start = tok.start
+			break
+		}
+	}
+	runes := []rune(text)
+	from := min(max(utf8.RuneCountInString(text[:start])-width/4, 0), len(runes)-width)
+	to := from + width
+	out := string(runes[from:to])
+	if from > 0 {
+		out = "…" + out
+	}
+	if to < len(runes) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func searchTestTasks() []Task {
+	return []Task{
+		NewTask(1, "Migración de la base de datos", "Pasar a SQLite"),
+		NewTask(2, "Canción de bienvenida", "Elegir la canción para la demo"),
+		NewTask(3, "Revisar datos", "La base de datos de pruebas tiene migraciones pendientes"),
+		NewTask(4, "Diseño", "Nada que ver"),
+	}
+}
+
+func searchIDs(t *testing.T, ix termIndex, q string) []int {
+	t.Helper()
+	clauses, err := parseSearchQuery(q)
+	if err != nil {
+		t.Fatalf("parseSearchQuery(%q): %v", q, err)
+	}
+	hits, err := searchTasks(ix, clauses)
+	if err != nil {
+		t.Fatalf("searchTasks(%q): %v", q, err)
+	}
+	ids := make([]int, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids
+}
+
+func TestSearchTasks(t *testing.T) {
+	// La tarea 5 llega en NFD: cada tilde es una marca combinante aparte.
+	tasks := append(searchTestTasks(), NewTask(5, "Reunio\u0301n de an\u0303o nuevo", ""))
+	ix := buildIndex(tasks)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"cancion", "2"},
+		{"CANCIÓN", "2"},
+		{"migracion", "1"},
+		{"migra*", "1,3"},
+		{"datos", "3,1"},
+		{"base datos", "1,3"},
+		{`"base de datos"`, "1,3"},
+		{`"datos de base"`, ""},
+		{`"base de datos" pruebas`, "3"},
+		{"inexistente", ""},
+		{"cancio\u0301n", "2"},
+		{"reunion", "5"},
+		{"REUNIÓN año", "5"},
+	}
+	for _, tt := range tests {
+		if got := joinIDs(searchIDs(t, ix, tt.query), ","); got != tt.want {
+			t.Errorf("search %q = %s, esperado %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	for _, q := range []string{"", "  ", `"sin cerrar`, "¿?"} {
+		if _, err := parseSearchQuery(q); err == nil {
+			t.Errorf("parseSearchQuery(%q) debería fallar", q)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	clauses, _ := parseSearchQuery("cancion migra*")
+	got := highlight("Canción: migraciones y más", clauses, "<", ">")
+	if want := "<Canción>: <migraciones> y más"; got != want {
+		t.Errorf("highlight = %q, esperado %q", got, want)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	clauses, _ := parseSearchQuery("objetivo")
+	text := "Un texto bastante largo que no dice nada relevante hasta que por fin aparece el objetivo y sigue un rato más"
+	if got := snippet(text, clauses, 30); got != "…ece el objetivo y sigue un rat…" {
+		t.Errorf("snippet = %q", got)
+	}
+	text = "Un texto bastante largo que no dice nada relevante hasta que por fin aparece el objetivo"
+	if got := snippet(text, clauses, 30); got != "…ue por fin aparece el objetivo" {
+		t.Errorf("snippet al final = %q", got)
+	}
+	if short := snippet("corto", clauses, 30); short != "corto" {
+		t.Errorf("snippet corto = %q", short)
+	}
+}
+
+// El índice solo vive en SQLite: guardar el archivo JSON borra el .idx que
+// dejaban las versiones anteriores.
+func TestJSONStoreRemovesOldIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	os.WriteFile(path+".idx", []byte(`{"docs": 0}`), 0o644)
+	s := &jsonStore{path: path}
+	if _, ok := Store(s).(indexedStore); ok {
+		t.Error("jsonStore no debería ofrecer un índice guardado")
+	}
+	if err := s.Save(searchTestTasks()); err != nil {
+		t.Fatalf("Error guardando: %v", err)
+	}
+	if _, err := os.Stat(path + ".idx"); !os.IsNotExist(err) {
+		t.Errorf("El índice antiguo sigue en disco: %v", err)
+	}
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultServeAddr = "localhost:8080"
+	maxRequestBody   = 1 << 20
+	shutdownTimeout  = 10 * time.Second
+)
+
+// writableTaskFields son los campos de Task que aceptan POST y PATCH; el
+// resto (id, fechas y registro de tiempo) los gestiona taskcli.
+var writableTaskFields = map[string]bool{
+	"title": true, "description": true, "status": true, "priority": true,
+	"due": true, "tags": true, "project": true, "parent_id": true,
+	"blocked_by": true, "links": true, "recurrence": true,
+}
+
+// httpError es un error con un código HTTP propio, para los casos que no
+// tienen equivalente en los errores de la CLI (412, 409...).
+type httpError struct {
+	Status int
+	Msg    string
+}
+
+func (e *httpError) Error() string {
+	return e.Msg
+}
+
+func httpErrorf(status int, format string, args ...any) error {
+	return &httpError{Status: status, Msg: fmt.Sprintf(format, args...)}
+}
+
+// httpStatus es el equivalente de exitCode para la API.
+func httpStatus(err error) int {
+	var httpErr *httpError
+	var notFound *NotFoundError
+	var invalidID *InvalidIDError
+	var validation *ValidationError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.Status
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.As(err, &invalidID), errors.As(err, &validation):
+		return http.StatusBadRequest
+	case errors.Is(err, errLockTimeout):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func contentHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// taskETag identifica una versión de la tarea: cambia con cualquier campo,
+// incluido UpdatedAt.
+func taskETag(t Task) string {
+	b, _ := json.Marshal(t)
+	return `"` + contentHash(b)[:16] + `"`
+}
+
+// checkIfMatch aplica la concurrencia optimista: si el cliente envía If-Match,
+// la tarea no debe haber cambiado desde que la leyó.
+func checkIfMatch(r *http.Request, t Task) error {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" {
+		return nil
+	}
+	etag := taskETag(t)
+	for _, candidate := range strings.Split(match, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return nil
+		}
+	}
+	return httpErrorf(http.StatusPreconditionFailed, "la tarea %d ha cambiado (ETag actual %s)", t.ID, etag)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeTaskJSON(w http.ResponseWriter, status int, t Task) {
+	w.Header().Set("ETag", taskETag(t))
+	writeJSON(w, status, t)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, httpStatus(err), map[string]string{"error": err.Error()})
+}
+
+// applyTaskJSON aplica a t los campos de body como un JSON merge patch
+// (RFC 7396): los campos ausentes no cambian y null los vacía. due acepta
+// también una fecha sola (AAAA-MM-DD), que vence al terminar ese día local
+// como con --due, y se guarda siempre en hora local.
+func applyTaskJSON(t Task, body []byte) (Task, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return t, validationErrorf("JSON inválido: %v", err)
+	}
+	base, err := json.Marshal(t)
+	if err != nil {
+		return t, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return t, err
+	}
+	for name, value := range patch {
+		if !writableTaskFields[name] {
+			return t, validationErrorf("el campo %s no se puede modificar", name)
+		}
+		var date string
+		if name == "due" && json.Unmarshal(value, &date) == nil {
+			if d, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+				value, _ = json.Marshal(endOfDay(d))
+			}
+		}
+		if string(value) == "null" {
+			delete(fields, name)
+		} else {
+			fields[name] = value
+		}
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return t, err
+	}
+	var out Task
+	if err := json.Unmarshal(merged, &out); err != nil {
+		return t, validationErrorf("JSON inválido: %v", err)
+	}
+	if out.Due != nil {
+		due := out.Due.Local()
+		out.Due = &due
+	}
+	return out, nil
+}
+
+// validateTaskFields aplica a una tarea recibida por la API las mismas
+// comprobaciones y normalizaciones que add y edit.
+func validateTaskFields(tasks []Task, t *Task) error {
+	if strings.TrimSpace(t.Title) == "" {
+		return validationErrorf("el título es requerido")
+	}
+	t.Tags = addTags(nil, t.Tags...)
+	project, err := parseProject(t.Project)
+	if err != nil {
+		return &ValidationError{Msg: err.Error()}
+	}
+	t.Project = project
+	if err := validateParent(tasks, t.ID, t.ParentID); err != nil {
+		return &ValidationError{Msg: err.Error()}
+	}
+	blockers := t.BlockedBy
+	t.BlockedBy = nil
+	for _, b := range blockers {
+		if err := validateDependency(tasks, t.ID, b); err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		t.BlockedBy = addBlockers(t.BlockedBy, b)
+	}
+	for i, l := range t.Links {
+		kind, err := normalizeLinkKind(l.Kind)
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		t.Links[i].Kind = kind
+	}
+	if t.Recurrence != "" {
+		rule, err := parseRecurrence(t.Recurrence)
+		if err != nil {
+			return &ValidationError{Msg: err.Error()}
+		}
+		t.Recurrence = rule.String()
+	}
+	return nil
+}
+
+// apiServer atiende la API REST. Cada petición abre el almacenamiento con
+// withStore; mu serializa las escrituras de la API para que la comprobación
+// de If-Match y el guardado no se intercalen con otra petición.
+type apiServer struct {
+	mu sync.Mutex
+}
+
+func newAPIHandler() http.Handler {
+	a := &apiServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", a.listTasks)
+	mux.HandleFunc("POST /tasks", a.createTask)
+	mux.HandleFunc("GET /tasks/{id}", a.getTask)
+	mux.HandleFunc("PATCH /tasks/{id}", a.patchTask)
+	mux.HandleFunc("DELETE /tasks/{id}", a.deleteTask)
+	mux.HandleFunc("POST /tasks/{id}/start", a.changeStatus(INPROGRESS))
+	mux.HandleFunc("POST /tasks/{id}/done", a.changeStatus(DONE))
+	return requireJSON(mux)
+}
+
+// requireJSON responde 415 a las peticiones con un cuerpo que no es
+// application/json. Un formulario de otra web solo puede enviar text/plain o
+// datos de formulario sin pedir permiso al servidor (CORS), así que esto
+// evita que cree o modifique tareas.
+func requireJSON(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ct := r.Header.Get("Content-Type")
+		if r.ContentLength != 0 || ct != "" {
+			if mediaType, _, err := mime.ParseMediaType(ct); err != nil || mediaType != "application/json" {
+				writeError(w, httpErrorf(http.StatusUnsupportedMediaType, "el cuerpo debe ser application/json"))
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// requireToken exige en cada petición la cabecera Authorization con el token
+// del servidor y responde 401 si falta o no coincide. Sin token no comprueba
+// nada: entonces httpServe solo escucha en localhost.
+func requireToken(h http.Handler, token string) http.Handler {
+	if token == "" {
+		return h
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, httpErrorf(http.StatusUnauthorized, "falta el token o no es válido"))
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin rechaza las peticiones cuyo Host no es la dirección en la que
+// escucha el servidor, que llegan a través de DNS rebinding, y las que traen
+// un Origin de otra web. Se admiten el host de addr, localhost, las IP y el
+// nombre del equipo con el puerto de ln: un dominio ajeno nunca coincide.
+func sameOrigin(h http.Handler, addr string, ln net.Addr) http.Handler {
+	_, port, _ := net.SplitHostPort(ln.String())
+	allowed := map[string]bool{}
+	names := []string{"localhost"}
+	if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+		names = append(names, host)
+	}
+	if name, err := os.Hostname(); err == nil {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		allowed[strings.ToLower(net.JoinHostPort(name, port))] = true
+	}
+	allowedHost := func(hostport string) bool {
+		host, p, err := net.SplitHostPort(hostport)
+		if err != nil {
+			host, p = hostport, "80"
+		}
+		return allowed[strings.ToLower(net.JoinHostPort(host, p))] || p == port && net.ParseIP(host) != nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host) {
+			writeError(w, httpErrorf(http.StatusForbidden, "Host no permitido: %s", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+				writeError(w, httpErrorf(http.StatusForbidden, "Origin no permitido: %s", origin))
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, httpErrorf(http.StatusRequestEntityTooLarge, "el cuerpo supera %d bytes", tooLarge.Limit)
+	}
+	return body, err
+}
+
+// listTasks admite ?status=todo|inprogress|done (repetible) y ?where= con el
+// mismo lenguaje que list --where.
+func (a *apiServer) listTasks(w http.ResponseWriter, r *http.Request) {
+	var q TaskQuery
+	for _, name := range r.URL.Query()["status"] {
+		status, err := parseStatus(strings.ReplaceAll(strings.ToUpper(name), "INPROGRESS", "IN_PROGRESS"))
+		if err != nil {
+			writeError(w, &ValidationError{Msg: err.Error()})
+			return
+		}
+		q.Statuses = append(q.Statuses, status)
+	}
+	if where := r.URL.Query().Get("where"); where != "" {
+		pred, err := parseQuery(where, timeNow())
+		if err != nil {
+			writeError(w, &ValidationError{Msg: err.Error()})
+			return
+		}
+		q.Match = pred
+	}
+	err := withStore(func(s Store) error {
+		tasks, err := s.Query(q)
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		if tasks == nil {
+			tasks = []Task{}
+		}
+		writeJSON(w, http.StatusOK, tasks)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// createTask guarda la tarea como TODO y aplica después el status pedido con
+// setStatus, igual que PATCH, para que DONE registre la hora de finalización y
+// cree la siguiente repetición.
+func (a *apiServer) createTask(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	task, err := applyTaskJSON(NewTask(0, "", ""), body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err = withStore(func(s Store) error {
+		tasks, err := s.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		if err := validateTaskFields(tasks, &task); err != nil {
+			return err
+		}
+		status := task.Status
+		task.Status = TODO
+		created, err := addTask(io.Discard, s, task)
+		if err != nil {
+			return err
+		}
+		if status != TODO {
+			if created, err = setStatusChecked(io.Discard, s, created.ID, status, nil); err != nil {
+				return err
+			}
+		}
+		w.Header().Set("Location", fmt.Sprintf("/tasks/%d", created.ID))
+		writeTaskJSON(w, http.StatusCreated, created)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+func (a *apiServer) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	err = withStore(func(s Store) error {
+		t, err := getTask(s, id)
+		if err != nil {
+			return err
+		}
+		if r.Header.Get("If-None-Match") == taskETag(t) {
+			w.Header().Set("ETag", taskETag(t))
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		writeTaskJSON(w, http.StatusOK, t)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// patchTask modifica los campos enviados y, si cambia, el status, todo en la
+// misma escritura. El cambio de status tiene los efectos de start y done: el
+// temporizador, la siguiente repetición y, al pasar a IN_PROGRESS, el 409 por
+// dependencias pendientes salvo con ?force=true.
+func (a *apiServer) patchTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	body, err := readBody(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err = withStore(func(s Store) error {
+		now := timeNow()
+		var change *statusChange
+		t, err := updateTask(s, id, func(t *Task) error {
+			if err := checkIfMatch(r, *t); err != nil {
+				return err
+			}
+			patched, err := applyTaskJSON(*t, body)
+			if err != nil {
+				return err
+			}
+			tasks, err := s.Load()
+			if err != nil {
+				return storageError("cargando tareas", err)
+			}
+			if err := validateTaskFields(tasks, &patched); err != nil {
+				return err
+			}
+			status := patched.Status
+			if status == INPROGRESS && t.Status != INPROGRESS && !force {
+				if err := checkBlockers(tasks, patched); err != nil {
+					return err
+				}
+			}
+			patched.Status = t.Status
+			*t = patched
+			t.UpdatedAt = now
+			if status != t.Status {
+				c := applyStatus(t, status, now)
+				change = &c
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			if t, err = finishStatus(io.Discard, s, t, *change, now); err != nil {
+				return err
+			}
+		}
+		writeTaskJSON(w, http.StatusOK, t)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// checkBlockers devuelve 409 si t tiene dependencias sin terminar. Usa las
+// dependencias de t, que pueden venir de la propia petición, en vez de las
+// guardadas.
+func checkBlockers(tasks []Task, t Task) error {
+	for i := range tasks {
+		if tasks[i].ID == t.ID {
+			tasks[i] = t
+		}
+	}
+	if pending := pendingBlockers(tasks, t.ID); len(pending) > 0 {
+		return httpErrorf(http.StatusConflict, "la tarea %d está bloqueada por %s; usa ?force=true para iniciarla igualmente", t.ID, joinIDs(pending, ", "))
+	}
+	return nil
+}
+
+// deleteTask rechaza con 409 las tareas con subtareas salvo con ?recursive=true.
+func (a *apiServer) deleteTask(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	recursive, _ := strconv.ParseBool(r.URL.Query().Get("recursive"))
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err = withStore(func(s Store) error {
+		err := removeTaskChecked(io.Discard, s, id, func(t Task, children []int) error {
+			if err := checkIfMatch(r, t); err != nil {
+				return err
+			}
+			if n := len(children); n > 0 && !recursive {
+				return httpErrorf(http.StatusConflict, "la tarea %d tiene %d subtareas; usa ?recursive=true para eliminarlas también", id, n)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// changeStatus atiende /start y /done; start con dependencias pendientes
+// devuelve 409 salvo con ?force=true.
+func (a *apiServer) changeStatus(status Status) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		err = withStore(func(s Store) error {
+			t, err := setStatusChecked(io.Discard, s, id, status, func(t Task) error {
+				if err := checkIfMatch(r, t); err != nil {
+					return err
+				}
+				if status != INPROGRESS || force {
+					return nil
+				}
+				tasks, err := s.Load()
+				if err != nil {
+					return storageError("cargando tareas", err)
+				}
+				return checkBlockers(tasks, t)
+			})
+			if err != nil {
+				return err
+			}
+			writeTaskJSON(w, http.StatusOK, t)
+			return nil
+		})
+		if err != nil {
+			writeError(w, err)
+		}
+	}
+}
+
+// serveHTTP atiende h en ln hasta que ctx termina y después espera a que
+// acaben las peticiones en curso.
+func serveHTTP(ctx context.Context, ln net.Listener, h http.Handler) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 5 * time.Second}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+var cmdServe = &cobra.Command{
+	Use:   "serve",
+	Short: "Servir las tareas como API REST en JSON o como servicio gRPC",
+	Long: `Sirve las tareas como API REST en JSON o, con --grpc, como servicio gRPC.
+
+Cualquiera que alcance el puerto de serve puede leer, cambiar y borrar todas
+las tareas. Por eso la API REST sin --token solo escucha en localhost; con él
+exige la cabecera "Authorization: Bearer <token>" en cada petición. Como la API
+REST no cifra, el token viaja en claro: fuera de una red de confianza conviene
+ponerla detrás de un proxy con TLS.
+
+serve --grpc sin --tls-cert, --tls-key y --token también escucha solo en
+localhost; para escuchar en la red hay que indicar los tres. Los clientes usan
+el mismo token (--token, $` + tokenEnvVar + ` o la clave token de config.toml)
+y --remote-ca si el certificado no es de una autoridad reconocida.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		if useGRPC, _ := cmd.Flags().GetBool("grpc"); useGRPC {
+			if storeBackend == remoteStoreBackend {
+				return validationErrorf("serve --grpc necesita un almacenamiento local, no --remote")
+			}
+			if !cmd.Flags().Changed("addr") {
+				addr = defaultGRPCAddr
+			}
+			certFile, _ := cmd.Flags().GetString("tls-cert")
+			keyFile, _ := cmd.Flags().GetString("tls-key")
+			opts, err := grpcServerOptions(addr, certFile, keyFile, authToken)
+			if err != nil {
+				return err
+			}
+			return runServer(addr, "Servicio gRPC escuchando en %s", func(ctx context.Context, ln net.Listener) error {
+				return serveGRPC(ctx, ln, opts...)
+			})
+		}
+		serve, err := httpServe(addr, authToken, requireToken(newAPIHandler(), authToken))
+		if err != nil {
+			return err
+		}
+		return runServer(addr, "API escuchando en http://%s", serve)
+	},
+}
+
+// httpServe adapta serveHTTP a runServer y protege h con sameOrigin. Como
+// serve --grpc, sin token solo admite escuchar en loopback: quien alcance el
+// puerto podría leer, cambiar o borrar las tareas. h debe exigir el token con
+// requireToken.
+func httpServe(addr, token string, h http.Handler) (func(context.Context, net.Listener) error, error) {
+	if token == "" && !loopbackAddr(addr) {
+		return nil, validationErrorf("escuchar en %s necesita --token; sin él solo puede escuchar en localhost", addr)
+	}
+	return func(ctx context.Context, ln net.Listener) error {
+		return serveHTTP(ctx, ln, sameOrigin(h, addr, ln.Addr()))
+	}, nil
+}
+
+// runServer comprueba el almacenamiento, escucha en addr y atiende con serve
+// hasta recibir SIGINT o SIGTERM; lo usan serve y web.
+func runServer(addr, banner string, serve func(context.Context, net.Listener) error) error {
+	// Abrir el almacenamiento al arrancar avisa de una configuración
+	// incorrecta antes de aceptar peticiones.
+	if err := withStore(func(s Store) error { return nil }); err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return validationErrorf("no se pudo escuchar en %s: %v", addr, err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf(banner+" (Ctrl+C para detener)\n", ln.Addr())
+	if err := serve(ctx, ln); err != nil {
+		return err
+	}
+	fmt.Println("Servidor detenido")
+	return nil
+}
+
+func init() {
+	cmdServe.Flags().String("addr", defaultServeAddr, "Dirección en la que escuchar, p. ej. :8080 (con --grpc, "+defaultGRPCAddr+")")
+	cmdServe.Flags().Bool("grpc", false, "Servir el servicio gRPC TaskService en lugar de la API REST")
+	cmdServe.Flags().String("tls-cert", "", "Certificado TLS de serve --grpc (PEM)")
+	cmdServe.Flags().String("tls-key", "", "Clave privada del certificado de --tls-cert (PEM)")
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func apiRequest(t *testing.T, h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeTask(t *testing.T, rec *httptest.ResponseRecorder) Task {
+	t.Helper()
+	var task Task
+	if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+		t.Fatalf("Respuesta no es una tarea: %v: %s", err, rec.Body)
+	}
+	return task
+}
+
+func TestAPICRUD(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newAPIHandler()
+
+	rec := apiRequest(t, h, "POST", "/tasks", `{"title":"Panel","priority":"high","tags":["#Web"],"project":"front"}`)
+	if rec.Code != http.StatusCreated || rec.Header().Get("Location") != "/tasks/1" {
+		t.Fatalf("POST = %d %s, Location %q", rec.Code, rec.Body, rec.Header().Get("Location"))
+	}
+	created := decodeTask(t, rec)
+	if created.ID != 1 || created.Priority != HIGH || len(created.Tags) != 1 || created.Tags[0] != "web" {
+		t.Errorf("Creada = %+v", created)
+	}
+	etag := rec.Header().Get("ETag")
+
+	if rec = apiRequest(t, h, "POST", "/tasks", `{"description":"sin título"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST sin título = %d, esperado 400", rec.Code)
+	}
+	if rec = apiRequest(t, h, "POST", "/tasks", `{"title":"x","parent_id":9}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST con padre inexistente = %d, esperado 400", rec.Code)
+	}
+
+	rec = apiRequest(t, h, "GET", "/tasks/1", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != etag {
+		t.Errorf("GET = %d, ETag %q, esperado %q", rec.Code, rec.Header().Get("ETag"), etag)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks/1", "", "If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("GET con If-None-Match = %d, esperado 304", rec.Code)
+	}
+
+	rec = apiRequest(t, h, "PATCH", "/tasks/1", `{"title":"Panel v2","project":null}`, "If-Match", etag)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", rec.Code, rec.Body)
+	}
+	patched := decodeTask(t, rec)
+	if patched.Title != "Panel v2" || patched.Project != "" || patched.Priority != HIGH {
+		t.Errorf("Modificada = %+v", patched)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Error("El ETag debería cambiar al modificar la tarea")
+	}
+	// Una segunda escritura con el ETag antiguo pierde la carrera.
+	if rec = apiRequest(t, h, "PATCH", "/tasks/1", `{"title":"Otro"}`, "If-Match", etag); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PATCH con ETag antiguo = %d, esperado 412", rec.Code)
+	}
+	if rec = apiRequest(t, h, "PATCH", "/tasks/1", `{"id":7}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PATCH de id = %d, esperado 400", rec.Code)
+	}
+	if rec = apiRequest(t, h, "PATCH", "/tasks/1", `{"status":"DONE"}`); rec.Code != http.StatusOK || decodeTask(t, rec).Status != DONE {
+		t.Errorf("PATCH de status = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = apiRequest(t, h, "GET", "/tasks?status=todo", "")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("GET /tasks?status=todo = %d %s, esperado []", rec.Code, rec.Body)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks?where=title~panel", ""); !strings.Contains(rec.Body.String(), "Panel v2") {
+		t.Errorf("GET /tasks?where = %s", rec.Body)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks?where=title~", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET con consulta inválida = %d, esperado 400", rec.Code)
+	}
+
+	if rec = apiRequest(t, h, "DELETE", "/tasks/1", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d %s", rec.Code, rec.Body)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET tras DELETE = %d, esperado 404", rec.Code)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks/abc", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("GET con ID inválido = %d, esperado 400", rec.Code)
+	}
+	if rec = apiRequest(t, h, "PUT", "/tasks/1", "{}"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT = %d, esperado 405", rec.Code)
+	}
+}
+
+func TestAPIStatusActions(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newAPIHandler()
+
+	blocked := NewTask(2, "Desplegar", "")
+	blocked.BlockedBy = []int{1}
+	child := NewTask(3, "Subtarea", "")
+	child.ParentID = 1
+	first := NewTask(1, "Informe", "")
+	first.Recurrence = "FREQ=WEEKLY"
+	saveTasks([]Task{first, blocked, child})
+
+	patch := `{"title":"Desplegar v2","status":"IN_PROGRESS"}`
+	if rec := apiRequest(t, h, "PATCH", "/tasks/2", patch); rec.Code != http.StatusConflict {
+		t.Errorf("PATCH a IN_PROGRESS bloqueado = %d, esperado 409", rec.Code)
+	}
+	if tasks, _ := loadTasks(); tasks[1].Title != "Desplegar" || tasks[1].Status != TODO {
+		t.Errorf("un PATCH rechazado no debería guardar nada: %+v", tasks[1])
+	}
+	if rec := apiRequest(t, h, "PATCH", "/tasks/2", `{"status":"IN_PROGRESS","blocked_by":[]}`); rec.Code != http.StatusOK {
+		t.Errorf("PATCH que quita la dependencia = %d %s", rec.Code, rec.Body)
+	}
+	if rec := apiRequest(t, h, "PATCH", "/tasks/2", `{"status":"TODO","blocked_by":[1]}`); rec.Code != http.StatusOK {
+		t.Errorf("PATCH de vuelta a TODO = %d %s", rec.Code, rec.Body)
+	}
+	rec := apiRequest(t, h, "PATCH", "/tasks/2?force=true", patch)
+	if got := decodeTask(t, rec); rec.Code != http.StatusOK || got.Title != "Desplegar v2" || !got.timerRunning() {
+		t.Errorf("PATCH forzado = %d %s", rec.Code, rec.Body)
+	}
+	rec = apiRequest(t, h, "PATCH", "/tasks/2", `{"status":"TODO"}`)
+	if got := decodeTask(t, rec); got.Status != TODO || got.timerRunning() {
+		t.Errorf("PATCH a TODO debería detener el temporizador: %s", rec.Body)
+	}
+
+	if rec := apiRequest(t, h, "POST", "/tasks/2/start", ""); rec.Code != http.StatusConflict {
+		t.Errorf("start bloqueado = %d, esperado 409", rec.Code)
+	}
+	rec = apiRequest(t, h, "POST", "/tasks/2/start?force=true", "")
+	if rec.Code != http.StatusOK || !decodeTask(t, rec).timerRunning() {
+		t.Errorf("start forzado = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = apiRequest(t, h, "POST", "/tasks/1/done", "")
+	if rec.Code != http.StatusOK || decodeTask(t, rec).Status != DONE {
+		t.Errorf("done = %d %s", rec.Code, rec.Body)
+	}
+	tasks, _ := loadTasks()
+	if len(tasks) != 4 || tasks[3].Recurrence != "FREQ=WEEKLY" {
+		t.Errorf("done de una tarea recurrente debería crear la siguiente: %+v", tasks)
+	}
+
+	if rec = apiRequest(t, h, "DELETE", "/tasks/1", ""); rec.Code != http.StatusConflict {
+		t.Errorf("DELETE con subtareas = %d, esperado 409", rec.Code)
+	}
+	if rec = apiRequest(t, h, "DELETE", "/tasks/1?recursive=true", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE recursivo = %d %s", rec.Code, rec.Body)
+	}
+	if tasks, _ = loadTasks(); len(tasks) != 2 {
+		t.Errorf("Tareas = %d, esperadas 2", len(tasks))
+	}
+
+	rec = apiRequest(t, h, "POST", "/tasks", `{"title":"Backup","status":"DONE","due":"2030-01-06","recurrence":"FREQ=WEEKLY"}`)
+	if got := decodeTask(t, rec); rec.Code != http.StatusCreated || got.Status != DONE || got.CompletedAt == nil {
+		t.Errorf("POST como DONE = %d %s", rec.Code, rec.Body)
+	}
+	if tasks, _ = loadTasks(); len(tasks) != 4 || tasks[3].Status != TODO || tasks[3].Recurrence != "FREQ=WEEKLY" {
+		t.Errorf("POST de una tarea recurrente como DONE debería crear la siguiente: %+v", tasks)
+	}
+}
+
+func TestServeAPIGracefulShutdown(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveHTTP(ctx, ln, newAPIHandler()) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/tasks")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /tasks = %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serveHTTP = %v, esperado nil tras el apagado", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serveHTTP no terminó tras cancelar el contexto")
+	}
+}
+
+// Un vencimiento enviado como fecha sola, o en UTC como lo haría un
+// navegador, debe seguir siendo un día para la CLI.
+func TestAPIDueDateOnly(t *testing.T) {
+	// Cambiar time.Local mientras siguen vivas las conexiones de otros tests
+	// es una carrera de datos, así que la zona UTC-5 se fija con TZ en un
+	// proceso aparte.
+	const zone = "Etc/GMT+5"
+	if os.Getenv("TZ") != zone {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestAPIDueDateOnly$", "-test.count=1", "-test.v")
+		cmd.Env = append(os.Environ(), "TZ="+zone)
+		out, err := cmd.CombinedOutput()
+		if err != nil || !strings.Contains(string(out), "--- PASS: TestAPIDueDateOnly") {
+			t.Fatalf("Con TZ=%s: %v\n%s", zone, err, out)
+		}
+		return
+	}
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newAPIHandler()
+
+	rec := apiRequest(t, h, "POST", "/tasks", `{"title":"Factura","due":"2026-05-01"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body)
+	}
+	created := decodeTask(t, rec)
+	if want := time.Date(2026, 5, 1, 23, 59, 59, 0, time.Local); created.Due == nil || !created.Due.Equal(want) {
+		t.Errorf("Due = %v, esperado %v", created.Due, want)
+	}
+
+	rec = apiRequest(t, h, "GET", "/tasks/1", "")
+	got := decodeTask(t, rec)
+	if got.Due == nil || formatDue(*got.Due) != "2026-05-01" {
+		t.Errorf("Tras GET, formatDue = %v, esperado 2026-05-01", got.Due)
+	}
+
+	if rec = apiRequest(t, h, "PATCH", "/tasks/1", `{"due":"2026-05-03T04:59:59Z"}`); rec.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", rec.Code, rec.Body)
+	}
+	tasks, _ := loadTasks()
+	if len(tasks) != 1 || tasks[0].Due == nil || formatDue(*tasks[0].Due) != "2026-05-02" {
+		t.Errorf("Vencimiento guardado = %v, esperado el día 2026-05-02", tasks[0].Due)
+	}
+	if _, offset := tasks[0].Due.Zone(); offset != -5*3600 {
+		t.Errorf("El vencimiento debería guardarse en hora local, offset %d", offset)
+	}
+}
+
+func TestAPIRejectsNonJSONBody(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newAPIHandler()
+
+	if rec := apiRequest(t, h, "POST", "/tasks", `{"title":"x"}`, "Content-Type", "text/plain"); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST text/plain = %d, esperado 415", rec.Code)
+	}
+	if rec := apiRequest(t, h, "POST", "/tasks", `{"title":"x"}`, "Content-Type", "application/json; charset=utf-8"); rec.Code != http.StatusCreated {
+		t.Errorf("POST application/json con charset = %d %s", rec.Code, rec.Body)
+	}
+	if rec := apiRequest(t, h, "POST", "/tasks/1/done", "", "Content-Type", "application/x-www-form-urlencoded"); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST /done con formulario = %d, esperado 415", rec.Code)
+	}
+	if tasks, _ := loadTasks(); len(tasks) != 1 || tasks[0].Status != TODO {
+		t.Errorf("Tareas = %+v, esperada solo una en TODO", tasks)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	ln := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	h := sameOrigin(ok, "localhost:8080", ln)
+	tests := []struct {
+		host, origin string
+		want         int
+	}{
+		{"localhost:8080", "", http.StatusNoContent},
+		{"127.0.0.1:8080", "http://127.0.0.1:8080", http.StatusNoContent},
+		{"[::1]:8080", "", http.StatusNoContent},
+		{"LOCALHOST:8080", "http://localhost:8080", http.StatusNoContent},
+		{"evil.example:8080", "", http.StatusForbidden},
+		{"localhost:9999", "", http.StatusForbidden},
+		{"localhost:8080", "http://evil.example", http.StatusForbidden},
+		{"localhost:8080", "null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/tasks", nil)
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Host %s, Origin %q = %d, esperado %d", tt.host, tt.origin, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestAPIToken(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := requireToken(newAPIHandler(), "secreto")
+
+	rec := apiRequest(t, h, "GET", "/tasks", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Errorf("GET sin token = %d, esperado 401 con WWW-Authenticate", rec.Code)
+	}
+	if rec = apiRequest(t, h, "POST", "/tasks", `{"title":"x"}`, "Authorization", "Bearer otro"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST con otro token = %d, esperado 401", rec.Code)
+	}
+	if tasks, _ := loadTasks(); len(tasks) != 0 {
+		t.Errorf("Una petición sin token no debería crear tareas: %+v", tasks)
+	}
+	if rec = apiRequest(t, h, "GET", "/tasks", "", "Authorization", "Bearer secreto"); rec.Code != http.StatusOK {
+		t.Errorf("GET con token = %d %s", rec.Code, rec.Body)
+	}
+
+	for _, tt := range []struct {
+		addr, token string
+		ok          bool
+	}{
+		{"localhost:8080", "", true},
+		{"127.0.0.1:8080", "", true},
+		{":8080", "", false},
+		{"0.0.0.0:8080", "", false},
+		{"0.0.0.0:8080", "secreto", true},
+	} {
+		if _, err := httpServe(tt.addr, tt.token, h); (err == nil) != tt.ok {
+			t.Errorf("httpServe(%s, token %t) = %v", tt.addr, tt.token != "", err)
+		}
+	}
+}
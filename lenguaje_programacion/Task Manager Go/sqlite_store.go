@@ -0,0 +1,738 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	registerStore("sqlite", func() (Store, error) {
+		path, err := sqliteFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return openSQLiteStore(path)
+	})
+}
+
+func sqliteFilePath() (string, error) {
+	path, err := tasksFilePath()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".db", nil
+}
+
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+	// run completa la migración con datos que no se pueden calcular en SQL.
+	run func(tx *sql.Tx) error
+}
+
+// Las migraciones se aplican en orden y nunca se modifican una vez publicadas;
+// los cambios de esquema se agregan como una versión nueva al final.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "crear tabla tasks",
+		stmts: []string{
+			`CREATE TABLE tasks (
+				id          INTEGER PRIMARY KEY,
+				title       TEXT    NOT NULL,
+				description TEXT    NOT NULL DEFAULT '',
+				status      TEXT    NOT NULL,
+				created_at  INTEGER NOT NULL,
+				updated_at  INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idx_tasks_status ON tasks(status)`,
+			`CREATE INDEX idx_tasks_created_at ON tasks(created_at)`,
+			`CREATE INDEX idx_tasks_updated_at ON tasks(updated_at)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "agregar prioridad",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0`,
+			`CREATE INDEX idx_tasks_priority ON tasks(priority)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "agregar vencimiento",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN due_at INTEGER`,
+			`CREATE INDEX idx_tasks_due_at ON tasks(due_at)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "agregar etiquetas",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 5,
+		name:    "agregar proyecto",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN project TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX idx_tasks_project ON tasks(project)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "agregar tarea padre",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0`,
+			`CREATE INDEX idx_tasks_parent_id ON tasks(parent_id)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "agregar dependencias",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN blocked_by TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 8,
+		name:    "agregar enlaces",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN links TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 9,
+		name:    "crear índice de búsqueda",
+		stmts: []string{
+			`CREATE TABLE search_terms (
+				term    TEXT    NOT NULL,
+				task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+				field   INTEGER NOT NULL,
+				pos     INTEGER NOT NULL,
+				PRIMARY KEY (term, task_id, field, pos)
+			) WITHOUT ROWID`,
+			`CREATE INDEX idx_search_terms_task_id ON search_terms(task_id)`,
+		},
+		run: reindexAll,
+	},
+	{
+		version: 10,
+		name:    "agregar registro de tiempo",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN worklog TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 11,
+		name:    "agregar repetición",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 12,
+		name:    "agregar fecha de finalización",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN completed_at INTEGER`,
+		},
+	},
+	{
+		version: 13,
+		name:    "guardar el último id",
+		stmts: []string{
+			`CREATE TABLE id_sequence (last_id INTEGER NOT NULL)`,
+			`INSERT INTO id_sequence (last_id) SELECT COALESCE(MAX(id), 0) FROM tasks`,
+		},
+	},
+	{
+		version: 14,
+		name:    "agregar fecha de inicio",
+		stmts: []string{
+			`ALTER TABLE tasks ADD COLUMN started_at INTEGER`,
+		},
+	},
+	{
+		// foldTerm quita ahora cualquier marca diacrítica y pliega los textos
+		// en NFD, así que los términos guardados antes pueden no coincidir.
+		version: 15,
+		name:    "reindexar con el nuevo plegado de acentos",
+		run:     reindexAll,
+	},
+}
+
+type sqliteStore struct {
+	db   *sql.DB
+	path string
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path, lockTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &sqliteStore{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *sqliteStore) schemaVersion() (int, error) {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT    NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return 0, err
+	}
+	var v sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v); err != nil {
+		return 0, err
+	}
+	return int(v.Int64), nil
+}
+
+// migrate toma el bloqueo antes de leer la versión: si dos procesos abren a la
+// vez una base de datos antigua, el segundo espera y ya no encuentra nada que
+// migrar en lugar de aplicar otra vez las mismas migraciones.
+func (s *sqliteStore) migrate() error {
+	lock, err := acquireLock(s.path+".lock", lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	current, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range m.stmts {
+			if _, err := tx.Exec(stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migración %d (%s): %w", m.version, m.name, err)
+			}
+		}
+		if m.run != nil {
+			if err := m.run(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migración %d (%s): %w", m.version, m.name, err)
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.version, m.name, time.Now().UnixNano()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskFields son las columnas de tasks salvo id, en el orden de taskValues y scanTask.
+var taskFields = []string{"title", "description", "status", "created_at", "updated_at", "priority", "due_at", "tags", "project", "parent_id", "blocked_by", "links", "worklog", "recurrence", "completed_at", "started_at"}
+
+var taskColumns = "id, " + strings.Join(taskFields, ", ")
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (Task, error) {
+	var t Task
+	var status string
+	var created, updated int64
+	var due, completed, started sql.NullInt64
+	var tags, blockedBy, links, worklog string
+	if err := row.Scan(&t.ID, &t.Title, &t.Description, &status, &created, &updated, &t.Priority, &due, &tags, &t.Project, &t.ParentID, &blockedBy, &links, &worklog, &t.Recurrence, &completed, &started); err != nil {
+		return Task{}, err
+	}
+	if err := decodeJSONColumn(tags, &t.Tags); err != nil {
+		return Task{}, err
+	}
+	if err := decodeJSONColumn(blockedBy, &t.BlockedBy); err != nil {
+		return Task{}, err
+	}
+	if err := decodeJSONColumn(links, &t.Links); err != nil {
+		return Task{}, err
+	}
+	if err := decodeJSONColumn(worklog, &t.Worklog); err != nil {
+		return Task{}, err
+	}
+	st, err := parseStatus(status)
+	if err != nil {
+		return Task{}, err
+	}
+	t.Status = st
+	t.CreatedAt = time.Unix(0, created)
+	t.UpdatedAt = time.Unix(0, updated)
+	t.Due = timeFromColumn(due)
+	t.CompletedAt = timeFromColumn(completed)
+	t.StartedAt = timeFromColumn(started)
+	return t, nil
+}
+
+func taskValues(t Task) []any {
+	return []any{t.Title, t.Description, t.Status.String(), t.CreatedAt.UnixNano(), t.UpdatedAt.UnixNano(), int(t.Priority), timeColumn(t.Due),
+		encodeJSONColumn(len(t.Tags), t.Tags), t.Project, t.ParentID,
+		encodeJSONColumn(len(t.BlockedBy), t.BlockedBy), encodeJSONColumn(len(t.Links), t.Links),
+		encodeJSONColumn(len(t.Worklog), t.Worklog), t.Recurrence, timeColumn(t.CompletedAt), timeColumn(t.StartedAt)}
+}
+
+// Las fechas opcionales se guardan en nanosegundos o como NULL.
+func timeColumn(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func timeFromColumn(n sql.NullInt64) *time.Time {
+	if !n.Valid {
+		return nil
+	}
+	t := time.Unix(0, n.Int64)
+	return &t
+}
+
+// Las listas se guardan como JSON en una columna TEXT; la cadena vacía
+// representa una lista vacía.
+func encodeJSONColumn(n int, v any) string {
+	if n == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func decodeJSONColumn(s string, v any) error {
+	if s == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), v)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func insertTaskRow(db execer, t Task) error {
+	args := append([]any{t.ID}, taskValues(t)...)
+	_, err := db.Exec(`INSERT INTO tasks (`+taskColumns+`) VALUES (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// indexTaskTerms reemplaza las entradas de búsqueda de t.
+func indexTaskTerms(db execer, t Task) error {
+	if _, err := db.Exec(`DELETE FROM search_terms WHERE task_id = ?`, t.ID); err != nil {
+		return err
+	}
+	for term, ps := range taskTerms(t) {
+		for _, p := range ps {
+			if _, err := db.Exec(`INSERT INTO search_terms (term, task_id, field, pos) VALUES (?, ?, ?, ?)`, term, t.ID, p.Field, p.Pos); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reindexAll solo lee las columnas indexadas: como parte de una migración no
+// puede depender de columnas agregadas en versiones posteriores.
+func reindexAll(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, title, description FROM tasks`)
+	if err != nil {
+		return err
+	}
+	var tasks []Task
+	for rows.Next() {
+		var t Task
+		if err := rows.Scan(&t.ID, &t.Title, &t.Description); err != nil {
+			rows.Close()
+			return err
+		}
+		tasks = append(tasks, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if err := indexTaskTerms(tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) queryTasks(where string, args ...any) ([]Task, error) {
+	rows, err := s.db.Query(`SELECT `+taskColumns+` FROM tasks `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tasks := []Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+func (s *sqliteStore) Load() ([]Task, error) {
+	return s.queryTasks("")
+}
+
+// reserveID devuelve un ID que nunca se ha usado. id_sequence recuerda el
+// último asignado aunque se elimine la tarea; MAX(id) cubre las filas que
+// Save guardó con su propio ID.
+func reserveID(db execer) (int, error) {
+	if _, err := db.Exec(`UPDATE id_sequence SET last_id = MAX(last_id, (SELECT COALESCE(MAX(id), 0) FROM tasks)) + 1`); err != nil {
+		return 0, err
+	}
+	var id int
+	err := db.QueryRow(`SELECT last_id FROM id_sequence`).Scan(&id)
+	return id, err
+}
+
+// raiseLastID marca como usados los IDs hasta id, p. ej. los de tareas
+// eliminadas del archivo JSON que copia migrate.
+func (s *sqliteStore) raiseLastID(id int) error {
+	_, err := s.db.Exec(`UPDATE id_sequence SET last_id = MAX(last_id, ?)`, id)
+	return err
+}
+
+func (s *sqliteStore) Save(tasks []Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tasks`); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, t := range tasks {
+		if t.ID == 0 {
+			t.ID, err = reserveID(tx)
+		}
+		if err == nil {
+			err = insertTaskRow(tx, t)
+		}
+		if err == nil {
+			err = indexTaskTerms(tx, t)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE id_sequence SET last_id = MAX(last_id, (SELECT COALESCE(MAX(id), 0) FROM tasks))`); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Get(id int) (Task, error) {
+	row := s.db.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id)
+	t, err := scanTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Task{}, os.ErrNotExist
+	}
+	return t, err
+}
+
+func (s *sqliteStore) Insert(t Task) (Task, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Task{}, err
+	}
+	t.ID, err = reserveID(tx)
+	if err == nil {
+		err = insertTaskRow(tx, t)
+	}
+	if err == nil {
+		err = indexTaskTerms(tx, t)
+	}
+	if err != nil {
+		tx.Rollback()
+		return Task{}, err
+	}
+	return t, tx.Commit()
+}
+
+func (s *sqliteStore) Update(t Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	args := append(taskValues(t), t.ID)
+	res, err := tx.Exec(`UPDATE tasks SET `+strings.Join(taskFields, " = ?, ")+` = ? WHERE id = ?`, args...)
+	if err == nil {
+		err = requireAffected(res)
+	}
+	if err == nil {
+		err = indexTaskTerms(tx, t)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Modify escribe antes de leer para tomar desde el principio el bloqueo de
+// escritura de SQLite, como BEGIN IMMEDIATE: otra transacción no puede
+// cambiar la tarea entre la lectura y el UPDATE.
+func (s *sqliteStore) Modify(id int, fn func(t *Task) error) (Task, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Task{}, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE tasks SET id = id WHERE id = ?`, id)
+	if err == nil {
+		err = requireAffected(res)
+	}
+	if err != nil {
+		return Task{}, err
+	}
+	t, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		return Task{}, err
+	}
+	if err := fn(&t); err != nil {
+		return Task{}, err
+	}
+	t.ID = id
+	args := append(taskValues(t), id)
+	if _, err := tx.Exec(`UPDATE tasks SET `+strings.Join(taskFields, " = ?, ")+` = ? WHERE id = ?`, args...); err != nil {
+		return Task{}, err
+	}
+	if err := indexTaskTerms(tx, t); err != nil {
+		return Task{}, err
+	}
+	return t, tx.Commit()
+}
+
+func (s *sqliteStore) Delete(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	if err == nil {
+		err = requireAffected(res)
+	}
+	if err == nil {
+		err = dropReferenceRows(tx, []int{id})
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// dropReferenceRows quita de las tareas restantes las dependencias y enlaces
+// hacia ids, dentro de la transacción que las elimina.
+func dropReferenceRows(tx *sql.Tx, ids []int) error {
+	rows, err := tx.Query(`SELECT ` + taskColumns + ` FROM tasks WHERE blocked_by != '' OR links != ''`)
+	if err != nil {
+		return err
+	}
+	var changed []Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if dropReferences(&t, ids) {
+			changed = append(changed, t)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, t := range changed {
+		if _, err := tx.Exec(`UPDATE tasks SET blocked_by = ?, links = ? WHERE id = ?`,
+			encodeJSONColumn(len(t.BlockedBy), t.BlockedBy), encodeJSONColumn(len(t.Links), t.Links), t.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTree toma el bloqueo de escritura igual que Modify y borra la tarea
+// con sus subtareas, y las referencias hacia ellas, en la misma transacción.
+func (s *sqliteStore) DeleteTree(id int, check func(t Task, children []int) error) ([]int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE tasks SET id = id WHERE id = ?`, id)
+	if err == nil {
+		err = requireAffected(res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t, err := scanTask(tx.QueryRow(`SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT id, parent_id FROM tasks WHERE parent_id != 0`)
+	if err != nil {
+		return nil, err
+	}
+	var tree []Task
+	for rows.Next() {
+		var node Task
+		if err := rows.Scan(&node.ID, &node.ParentID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tree = append(tree, node)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	children := descendantIDs(tree, id)
+	if err := check(t, children); err != nil {
+		return nil, err
+	}
+	ids := append([]any{id}, intsToAny(children)...)
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE id IN (`+placeholders(len(ids))+`)`, ids...); err != nil {
+		return nil, err
+	}
+	if err := dropReferenceRows(tx, append([]int{id}, children...)); err != nil {
+		return nil, err
+	}
+	return children, tx.Commit()
+}
+
+func intsToAny(ids []int) []any {
+	out := make([]any, len(ids))
+	for i, id := range ids {
+		out[i] = id
+	}
+	return out
+}
+
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return os.ErrNotExist
+	}
+	return nil
+}
+
+func (s *sqliteStore) Query(q TaskQuery) ([]Task, error) {
+	var conds []string
+	var args []any
+	if len(q.Statuses) > 0 {
+		conds = append(conds, "status IN ("+placeholders(len(q.Statuses))+")")
+		for _, st := range q.Statuses {
+			args = append(args, st.String())
+		}
+	}
+	if len(q.IDs) > 0 {
+		conds = append(conds, "id IN ("+placeholders(len(q.IDs))+")")
+		for _, id := range q.IDs {
+			args = append(args, id)
+		}
+	}
+	var where string
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	tasks, err := s.queryTasks(where, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := []Task{}
+	for _, t := range tasks {
+		if q.matches(t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *sqliteStore) SearchIndex() (termIndex, error) {
+	return sqliteIndex{s.db}, nil
+}
+
+type sqliteIndex struct {
+	db *sql.DB
+}
+
+func (ix sqliteIndex) lookup(term string, prefix bool) (postings, error) {
+	query, args := `SELECT task_id, field, pos FROM search_terms WHERE term = ?`, []any{term}
+	if prefix {
+		// 0xff no aparece en UTF-8, así que acota todos los términos con ese prefijo.
+		query, args = `SELECT task_id, field, pos FROM search_terms WHERE term >= ? AND term < ?`, []any{term, term + "\xff"}
+	}
+	rows, err := ix.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := postings{}
+	for rows.Next() {
+		var id int
+		var p posting
+		if err := rows.Scan(&id, &p.Field, &p.Pos); err != nil {
+			return nil, err
+		}
+		out[id] = append(out[id], p)
+	}
+	return out, rows.Err()
+}
+
+func (ix sqliteIndex) docCount() (int, error) {
+	var n int
+	err := ix.db.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&n)
+	return n, err
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
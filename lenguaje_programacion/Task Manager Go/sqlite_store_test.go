@@ -0,0 +1,344 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStoreCRUD(t *testing.T) {
+	s, err := openSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer s.Close()
+
+	first, err := s.Insert(NewTask(0, "Primera", "Desc"))
+	if err != nil {
+		t.Fatalf("Error insertando: %v", err)
+	}
+	second, err := s.Insert(NewTask(0, "Segunda", ""))
+	if err != nil {
+		t.Fatalf("Error insertando: %v", err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("IDs = %d,%d, esperados 1,2", first.ID, second.ID)
+	}
+
+	second.Status = INPROGRESS
+	second.Priority = URGENT
+	due := endOfDay(time.Now())
+	second.Due = &due
+	second.Tags = []string{"backend", "api"}
+	second.Project = "backend.api"
+	second.ParentID = 1
+	second.BlockedBy = []int{1}
+	second.Links = []Link{{Kind: "related", ID: 1}}
+	second.startTimer(time.Unix(1700000000, 0))
+	second.Recurrence = "FREQ=WEEKLY;BYDAY=MO"
+	started, completed := time.Unix(1700000000, 0), time.Unix(1700003600, 0)
+	second.StartedAt = &started
+	second.CompletedAt = &completed
+	if err := s.Update(second); err != nil {
+		t.Fatalf("Error actualizando: %v", err)
+	}
+	got, err := s.Get(2)
+	if err != nil {
+		t.Fatalf("Error obteniendo: %v", err)
+	}
+	if got.Status != INPROGRESS || got.Title != "Segunda" || got.Priority != URGENT {
+		t.Errorf("Tarea = %+v, esperada Segunda IN_PROGRESS URGENT", got)
+	}
+	if got.Due == nil || !got.Due.Equal(due) {
+		t.Errorf("Due = %v, esperado %v", got.Due, due)
+	}
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(completed) {
+		t.Errorf("CompletedAt = %v, esperado %v", got.CompletedAt, completed)
+	}
+	if got.StartedAt == nil || !got.StartedAt.Equal(started) {
+		t.Errorf("StartedAt = %v, esperado %v", got.StartedAt, started)
+	}
+	if got.Project != "backend.api" || got.ParentID != 1 {
+		t.Errorf("Project, ParentID = %q, %d; esperado backend.api, 1", got.Project, got.ParentID)
+	}
+	if len(got.BlockedBy) != 1 || got.BlockedBy[0] != 1 {
+		t.Errorf("BlockedBy = %v, esperado [1]", got.BlockedBy)
+	}
+	if len(got.Links) != 1 || got.Links[0] != (Link{Kind: "related", ID: 1}) {
+		t.Errorf("Links = %v, esperado [related 1]", got.Links)
+	}
+	if !got.timerRunning() || !got.Worklog[0].Start.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Worklog = %+v, esperado un temporizador en marcha", got.Worklog)
+	}
+	if got.Recurrence != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("Recurrence = %q, esperado FREQ=WEEKLY;BYDAY=MO", got.Recurrence)
+	}
+	if !got.HasAllTags([]string{"backend", "api"}) || len(got.Tags) != 2 {
+		t.Errorf("Tags = %v, esperadas [backend api]", got.Tags)
+	}
+	if first, _ := s.Get(1); first.Due != nil || first.Tags != nil {
+		t.Errorf("Tarea 1 = %+v, sin vencimiento ni etiquetas", first)
+	}
+	if !got.CreatedAt.Equal(second.CreatedAt) {
+		t.Errorf("CreatedAt = %v, esperado %v", got.CreatedAt, second.CreatedAt)
+	}
+
+	inProgress, err := s.Query(TaskQuery{Statuses: []Status{INPROGRESS}})
+	if err != nil {
+		t.Fatalf("Error consultando: %v", err)
+	}
+	if len(inProgress) != 1 || inProgress[0].ID != 2 {
+		t.Errorf("Consulta IN_PROGRESS = %v, esperada solo la tarea 2", inProgress)
+	}
+
+	if err := s.Delete(1); err != nil {
+		t.Fatalf("Error eliminando: %v", err)
+	}
+	if _, err := s.Get(1); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get tras Delete = %v, esperado os.ErrNotExist", err)
+	}
+	if err := s.Delete(1); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Delete inexistente = %v, esperado os.ErrNotExist", err)
+	}
+}
+
+func TestSQLiteStoreDeleteTree(t *testing.T) {
+	s, err := openSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer s.Close()
+	tasks := subtaskTestTasks()
+	tasks[4].BlockedBy = []int{3}
+	tasks[4].Links = []Link{{Kind: defaultLinkKind, ID: 2}}
+	if err := s.Save(tasks); err != nil {
+		t.Fatalf("Error guardando: %v", err)
+	}
+
+	refuse := errors.New("tiene subtareas")
+	if _, err := s.DeleteTree(1, func(Task, []int) error { return refuse }); !errors.Is(err, refuse) {
+		t.Errorf("DeleteTree rechazado = %v, esperado el error de check", err)
+	}
+	if tasks, _ := s.Load(); len(tasks) != 5 {
+		t.Fatalf("Un check fallido no debería eliminar nada, quedan %d", len(tasks))
+	}
+
+	children, err := s.DeleteTree(1, func(Task, []int) error { return nil })
+	if err != nil || len(children) != 3 {
+		t.Fatalf("DeleteTree = %v, %v, esperadas 3 subtareas", children, err)
+	}
+	if tasks, _ := s.Load(); len(tasks) != 1 || tasks[0].ID != 5 || len(tasks[0].BlockedBy) != 0 || len(tasks[0].Links) != 0 {
+		t.Errorf("Tareas restantes = %+v, esperada solo la 5 sin referencias a las eliminadas", tasks)
+	}
+	if err := s.Delete(5); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if added, err := s.Insert(NewTask(0, "Nueva", "")); err != nil || added.ID != 6 {
+		t.Errorf("Insert = %d, %v; esperado el ID 6, sin reutilizar los eliminados", added.ID, err)
+	}
+	ix, _ := s.SearchIndex()
+	if got := searchIDs(t, ix, "nieta"); len(got) != 0 {
+		t.Errorf("El índice conserva subtareas eliminadas: %v", got)
+	}
+	if _, err := s.DeleteTree(1, func(Task, []int) error { return nil }); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DeleteTree inexistente = %v, esperado os.ErrNotExist", err)
+	}
+}
+
+func TestSQLiteStoreSearchIndex(t *testing.T) {
+	s, err := openSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Save(searchTestTasks()); err != nil {
+		t.Fatalf("Error guardando: %v", err)
+	}
+	ix, _ := s.SearchIndex()
+	if got := joinIDs(searchIDs(t, ix, "migra*"), ","); got != "1,3" {
+		t.Errorf("search migra* = %s, esperado 1,3", got)
+	}
+	if got := joinIDs(searchIDs(t, ix, `"base de datos"`), ","); got != "1,3" {
+		t.Errorf("search frase = %s, esperado 1,3", got)
+	}
+
+	task, _ := s.Get(4)
+	task.Title = "Diseño de la canción"
+	if err := s.Update(task); err != nil {
+		t.Fatalf("Error actualizando: %v", err)
+	}
+	if err := s.Delete(2); err != nil {
+		t.Fatalf("Error eliminando: %v", err)
+	}
+	inserted, _ := s.Insert(NewTask(0, "Otra canción", ""))
+	if got := joinIDs(searchIDs(t, ix, "cancion"), ","); got != "4,5" || inserted.ID != 5 {
+		t.Errorf("search cancion tras cambios = %s, esperado 4,5", got)
+	}
+}
+
+func TestSQLiteStoreMigrationsIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	for i := 0; i < 2; i++ {
+		s, err := openSQLiteStore(path)
+		if err != nil {
+			t.Fatalf("Apertura %d: %v", i+1, err)
+		}
+		v, err := s.schemaVersion()
+		if err != nil {
+			t.Fatalf("Error leyendo versión: %v", err)
+		}
+		if v != migrations[len(migrations)-1].version {
+			t.Errorf("Versión = %d, esperada %d", v, migrations[len(migrations)-1].version)
+		}
+		s.Close()
+	}
+}
+
+func TestCmdMigrate(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	tasks := []Task{
+		NewTask(1, "Tarea 1", ""),
+		NewTask(4, "Tarea 4", "Desc"),
+		NewTask(6, "Eliminada", ""),
+	}
+	tasks[1].Status = DONE
+	saveTasks(tasks)
+
+	captureOutput(func() {
+		cmdRemove.RunE(cmdRemove, []string{"6"})
+		cmdMigrate.RunE(cmdMigrate, []string{})
+	})
+
+	path, _ := sqliteFilePath()
+	s, err := openSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer s.Close()
+	migrated, err := s.Load()
+	if err != nil {
+		t.Fatalf("Error cargando: %v", err)
+	}
+	if len(migrated) != 2 || migrated[1].ID != 4 || migrated[1].Status != DONE {
+		t.Errorf("Tareas migradas = %+v", migrated)
+	}
+
+	next, err := s.Insert(NewTask(0, "Nueva", ""))
+	if err != nil {
+		t.Fatalf("Error insertando: %v", err)
+	}
+	if next.ID != 7 {
+		t.Errorf("ID tras migrar = %d, esperado 7: el 6 ya se usó en el archivo JSON", next.ID)
+	}
+}
+
+// openSQLiteStoreAt crea en path una base de datos con las migraciones hasta
+// version, como la dejaría una versión anterior de taskcli.
+func openSQLiteStoreAt(t *testing.T, path string, version int) *sqliteStore {
+	t.Helper()
+	defer func(all []migration) { migrations = all }(migrations)
+	migrations = migrations[:version]
+	s, err := openSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Error creando la base de datos en la versión %d: %v", version, err)
+	}
+	return s
+}
+
+// Las migraciones posteriores a la del índice de búsqueda agregan columnas a
+// tasks; reindexAll no puede leerlas al actualizar una base de datos anterior.
+func TestSQLiteStoreMigrateFromOldSchema(t *testing.T) {
+	const searchVersion = 9
+	for _, from := range []int{searchVersion - 1, searchVersion} {
+		t.Run(fmt.Sprintf("desde la versión %d", from), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.db")
+			old := openSQLiteStoreAt(t, path, searchVersion-1)
+			now := time.Now().UnixNano()
+			for _, row := range []struct {
+				id                         int
+				title, description, status string
+				priority                   Priority
+				tags                       string
+			}{
+				{1, "Migración de la base de datos", "Revisar índices", "TODO", HIGH, `["db"]`},
+				{2, "Diseño", "Logo nuevo", "DONE", LOW, ""},
+			} {
+				if _, err := old.db.Exec(`INSERT INTO tasks (id, title, description, status, created_at, updated_at, priority, tags) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+					row.id, row.title, row.description, row.status, now, now, int(row.priority), row.tags); err != nil {
+					t.Fatalf("Error insertando: %v", err)
+				}
+			}
+			old.Close()
+			if from == searchVersion {
+				openSQLiteStoreAt(t, path, searchVersion).Close()
+			}
+
+			s, err := openSQLiteStore(path)
+			if err != nil {
+				t.Fatalf("Error migrando desde la versión %d: %v", from, err)
+			}
+			defer s.Close()
+			if v, _ := s.schemaVersion(); v != migrations[len(migrations)-1].version {
+				t.Errorf("Versión = %d, esperada %d", v, migrations[len(migrations)-1].version)
+			}
+			tasks, err := s.Load()
+			if err != nil || len(tasks) != 2 || tasks[0].Priority != HIGH || len(tasks[0].Tags) != 1 || tasks[1].Status != DONE || len(tasks[0].Worklog) != 0 || tasks[0].Recurrence != "" {
+				t.Fatalf("Tareas migradas = %+v, %v", tasks, err)
+			}
+			ix, err := s.SearchIndex()
+			if err != nil {
+				t.Fatalf("SearchIndex: %v", err)
+			}
+			if got := joinIDs(searchIDs(t, ix, "migracion"), ","); got != "1" {
+				t.Errorf("search migracion = %s, esperado 1", got)
+			}
+			if got := joinIDs(searchIDs(t, ix, "logo"), ","); got != "2" {
+				t.Errorf("search logo = %s, esperado 2", got)
+			}
+			if added, err := s.Insert(NewTask(0, "Tras migrar", "")); err != nil || added.ID != 3 {
+				t.Errorf("Insert = %d, %v; esperado el ID 3", added.ID, err)
+			}
+		})
+	}
+}
+
+func TestSQLiteStoreConcurrentMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	openSQLiteStoreAt(t, path, 1).Close()
+
+	const procs = 8
+	errs := make(chan error, procs)
+	for i := 0; i < procs; i++ {
+		go func() {
+			s, err := openSQLiteStore(path)
+			if err == nil {
+				s.Close()
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < procs; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Apertura simultánea: %v", err)
+		}
+	}
+
+	s, err := openSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Error abriendo base de datos: %v", err)
+	}
+	defer s.Close()
+	var applied int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil {
+		t.Fatalf("Error contando migraciones: %v", err)
+	}
+	if applied != len(migrations) {
+		t.Errorf("Migraciones aplicadas = %d, esperadas %d", applied, len(migrations))
+	}
+}
@@ -3,54 +3,365 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const storeDirName = ".taskcli"
 const storeFileName = "tasks.json"
+const maxBackups = 5
+
+func init() {
+	registerStore("json", func() (Store, error) {
+		path, err := tasksFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonStore{path: path}, nil
+	})
+}
 
 func tasksFilePath() (string, error) {
-	home, err := os.UserHomeDir()
+	path, err := resolveTasksFile()
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(home, storeDirName)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, storeFileName), nil
+	return path, nil
 }
 
-func loadTasks() ([]Task, error) {
-	path, err := tasksFilePath()
-	if err != nil {
-		return nil, err
-	}
-	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+type jsonStore struct {
+	path string
+}
+
+func (s *jsonStore) Load() ([]Task, error) {
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
 		return []Task{}, nil
 	}
-	b, err := os.ReadFile(path)
+	b, err := os.ReadFile(s.path)
 	if err != nil {
 		return nil, err
 	}
+	tasks, err := decodeTasks(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s está dañado (%w); ejecuta 'taskcli repair' para restaurar la última copia válida", s.path, err)
+	}
+	return tasks, nil
+}
+
+func decodeTasks(b []byte) ([]Task, error) {
 	var tasks []Task
 	if err := json.Unmarshal(b, &tasks); err != nil {
 		return nil, err
 	}
+	if tasks == nil {
+		tasks = []Task{}
+	}
 	return tasks, nil
 }
 
-func saveTasks(tasks []Task) error {
-	path, err := tasksFilePath()
+func (s *jsonStore) lock() (*fileLock, error) {
+	return acquireLock(s.path+".lock", lockTimeout)
+}
+
+// modify ejecuta el ciclo leer-modificar-guardar con el bloqueo tomado, para
+// que invocaciones concurrentes de taskcli no pierdan cambios ni repitan IDs.
+func (s *jsonStore) modify(fn func(tasks []Task) ([]Task, error)) error {
+	l, err := s.lock()
 	if err != nil {
 		return err
 	}
+	defer l.Release()
+	tasks, err := s.Load()
+	if err != nil {
+		return err
+	}
+	tasks, err = fn(tasks)
+	if err != nil {
+		return err
+	}
+	return s.save(tasks)
+}
+
+func (s *jsonStore) Save(tasks []Task) error {
+	l, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	return s.save(tasks)
+}
+
+func (s *jsonStore) save(tasks []Task) error {
 	b, err := json.MarshalIndent(tasks, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, b, 0o644)
+	// El último ID se guarda antes que las tareas: si algo falla entre
+	// medias, como mucho se salta un ID, nunca se repite.
+	last, err := s.lastID(tasks)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.lastIDPath(), []byte(strconv.Itoa(last)+"\n"), 0o644); err != nil {
+		return err
+	}
+	if err := rotateBackups(s.path); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, b, 0o644); err != nil {
+		return err
+	}
+	// Las versiones anteriores guardaban un índice de búsqueda junto al
+	// archivo; search ya no lo usa.
+	if err := os.Remove(s.path + ".idx"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *jsonStore) lastIDPath() string {
+	return s.path + ".lastid"
+}
+
+// lastID devuelve el mayor ID asignado hasta ahora: el guardado junto al
+// archivo, que recuerda los de tareas eliminadas, o el mayor de tasks si es
+// más alto, p. ej. en un archivo anterior a .lastid o editado a mano.
+func (s *jsonStore) lastID(tasks []Task) (int, error) {
+	last := nextID(tasks) - 1
+	b, err := os.ReadFile(s.lastIDPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return last, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, fmt.Errorf("%s está dañado: %w", s.lastIDPath(), err)
+	}
+	return max(n, last), nil
+}
+
+func (s *jsonStore) Get(id int) (Task, error) {
+	tasks, err := s.Load()
+	if err != nil {
+		return Task{}, err
+	}
+	i, err := findTaskIndexByID(tasks, id)
+	if err != nil {
+		return Task{}, err
+	}
+	return tasks[i], nil
+}
+
+func (s *jsonStore) Insert(t Task) (Task, error) {
+	err := s.modify(func(tasks []Task) ([]Task, error) {
+		last, err := s.lastID(tasks)
+		if err != nil {
+			return nil, err
+		}
+		t.ID = last + 1
+		return append(tasks, t), nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return t, nil
+}
+
+func (s *jsonStore) Update(t Task) error {
+	return s.modify(func(tasks []Task) ([]Task, error) {
+		i, err := findTaskIndexByID(tasks, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		tasks[i] = t
+		return tasks, nil
+	})
+}
+
+func (s *jsonStore) Modify(id int, fn func(t *Task) error) (Task, error) {
+	var out Task
+	err := s.modify(func(tasks []Task) ([]Task, error) {
+		i, err := findTaskIndexByID(tasks, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(&tasks[i]); err != nil {
+			return nil, err
+		}
+		out = tasks[i]
+		return tasks, nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return out, nil
+}
+
+func (s *jsonStore) Delete(id int) error {
+	return s.modify(func(tasks []Task) ([]Task, error) {
+		if _, err := findTaskIndexByID(tasks, id); err != nil {
+			return nil, err
+		}
+		return withoutTasks(tasks, []int{id}), nil
+	})
+}
+
+func (s *jsonStore) DeleteTree(id int, check func(t Task, children []int) error) ([]int, error) {
+	var children []int
+	err := s.modify(func(tasks []Task) ([]Task, error) {
+		i, err := findTaskIndexByID(tasks, id)
+		if err != nil {
+			return nil, err
+		}
+		children = descendantIDs(tasks, id)
+		if err := check(tasks[i], children); err != nil {
+			return nil, err
+		}
+		return withoutTasks(tasks, append([]int{id}, children...)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// withoutTasks quita de tasks las tareas ids y las referencias que el resto
+// tenía hacia ellas.
+func withoutTasks(tasks []Task, ids []int) []Task {
+	out := tasks[:0]
+	for _, t := range tasks {
+		if containsID(ids, t.ID) {
+			continue
+		}
+		dropReferences(&t, ids)
+		out = append(out, t)
+	}
+	return out
+}
+
+func (s *jsonStore) Query(q TaskQuery) ([]Task, error) {
+	tasks, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	out := []Task{}
+	for _, t := range tasks {
+		if q.matches(t) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d.bak", path, n)
+}
+
+// rotateBackups guarda el archivo actual como .1.bak desplazando las copias
+// anteriores; un archivo dañado nunca reemplaza a una copia válida.
+func rotateBackups(path string) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err := decodeTasks(b); err != nil {
+		return nil
+	}
+	for n := maxBackups - 1; n >= 1; n-- {
+		err := os.Rename(backupPath(path, n), backupPath(path, n+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return writeFileAtomic(backupPath(path, 1), b, 0o644)
+}
+
+// writeFileAtomic escribe en un temporal del mismo directorio, hace fsync y
+// lo renombra sobre path, de modo que un fallo a mitad nunca deja el archivo truncado.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) {
+		return err
+	}
+	return nil
+}
+
+// repairTasksFile restaura path desde la copia válida más reciente si no se
+// puede decodificar. Devuelve la copia usada, o "" si el archivo estaba sano.
+func repairTasksFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if _, err := decodeTasks(b); err == nil {
+		return "", nil
+	}
+	for n := 1; n <= maxBackups; n++ {
+		bak := backupPath(path, n)
+		data, err := os.ReadFile(bak)
+		if err != nil {
+			continue
+		}
+		if _, err := decodeTasks(data); err != nil {
+			continue
+		}
+		if err := os.WriteFile(path+".corrupt", b, 0o644); err != nil {
+			return "", err
+		}
+		if err := writeFileAtomic(path, data, 0o644); err != nil {
+			return "", err
+		}
+		return bak, nil
+	}
+	return "", fmt.Errorf("no hay copias válidas de %s", path)
 }
 
 func nextID(tasks []Task) int {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -207,3 +208,88 @@ func TestSaveTasksJSONFormat(t *testing.T) {
 		t.Errorf("Se esperaba 1 tarea, obtenidas %d", len(decoded))
 	}
 }
+
+func TestSaveTasksRotatesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	s := &jsonStore{path: path}
+
+	for i := 1; i <= maxBackups+2; i++ {
+		tasks := make([]Task, i)
+		for j := range tasks {
+			tasks[j] = NewTask(j+1, "Tarea", "")
+		}
+		if err := s.Save(tasks); err != nil {
+			t.Fatalf("Error guardando: %v", err)
+		}
+	}
+
+	for n := 1; n <= maxBackups; n++ {
+		data, err := os.ReadFile(backupPath(path, n))
+		if err != nil {
+			t.Fatalf("Copia %d: %v", n, err)
+		}
+		tasks, err := decodeTasks(data)
+		if err != nil {
+			t.Fatalf("Copia %d inválida: %v", n, err)
+		}
+		if expected := maxBackups + 2 - n; len(tasks) != expected {
+			t.Errorf("Copia %d tiene %d tareas, esperadas %d", n, len(tasks), expected)
+		}
+	}
+	if _, err := os.Stat(backupPath(path, maxBackups+1)); !os.IsNotExist(err) {
+		t.Error("No deberían guardarse más de maxBackups copias")
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("Quedó un temporal: %s", e.Name())
+		}
+	}
+}
+
+func TestRepairTasksFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	s := &jsonStore{path: path}
+
+	s.Save([]Task{NewTask(1, "Buena", "")})
+	s.Save([]Task{NewTask(1, "Buena", ""), NewTask(2, "Otra", "")})
+	os.WriteFile(path, []byte(`[{"id": 1, "title": "trunc`), 0o644)
+
+	if _, err := s.Load(); err == nil {
+		t.Fatal("Se esperaba error cargando un archivo dañado")
+	}
+
+	bak, err := repairTasksFile(path)
+	if err != nil {
+		t.Fatalf("Error reparando: %v", err)
+	}
+	if bak != backupPath(path, 1) {
+		t.Errorf("Copia usada = %s, esperada %s", bak, backupPath(path, 1))
+	}
+
+	tasks, err := s.Load()
+	if err != nil {
+		t.Fatalf("Error cargando tras reparar: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Buena" {
+		t.Errorf("Tareas restauradas = %+v, esperada solo 'Buena'", tasks)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("No se conservó el archivo dañado: %v", err)
+	}
+
+	bak, err = repairTasksFile(path)
+	if err != nil || bak != "" {
+		t.Errorf("Reparar un archivo sano = (%q, %v), esperado (\"\", nil)", bak, err)
+	}
+}
+
+func TestRepairTasksFileWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	os.WriteFile(path, []byte("{"), 0o644)
+
+	if _, err := repairTasksFile(path); err == nil {
+		t.Error("Se esperaba error sin copias válidas")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+type Store interface {
+	Load() ([]Task, error)
+	Save(tasks []Task) error
+	Get(id int) (Task, error)
+	// Insert guarda t con un ID nuevo. Los IDs nunca se reutilizan, aunque se
+	// elimine la tarea que lo tenía: otra tarea o un script podría seguir
+	// refiriéndose a ella.
+	Insert(t Task) (Task, error)
+	Update(t Task) error
+	// Modify aplica fn a la tarea id y la guarda sin que otro proceso pueda
+	// modificarla entre medias; fn puede consultar el resto con Load. Si fn
+	// devuelve un error no se guarda nada.
+	Modify(id int, fn func(t *Task) error) (Task, error)
+	// Delete elimina la tarea id y quita las dependencias y enlaces que otras
+	// tareas tenían hacia ella en la misma escritura.
+	Delete(id int) error
+	// DeleteTree elimina la tarea id y todas sus subtareas de una vez, igual
+	// que Delete, y devuelve los IDs de las subtareas. check recibe la tarea y
+	// sus subtareas antes de borrar nada; si devuelve un error no se elimina
+	// ninguna.
+	DeleteTree(id int, check func(t Task, children []int) error) ([]int, error)
+	Query(q TaskQuery) ([]Task, error)
+	Close() error
+}
+
+// statusApplyingStore lo implementan los almacenamientos cuyo servidor aplica
+// él mismo los cambios de estado, como remote: setStatus no crea entonces la
+// siguiente repetición para no duplicarla.
+type statusApplyingStore interface {
+	appliesStatusChanges()
+}
+
+type TaskQuery struct {
+	Statuses []Status
+	IDs      []int
+	Match    func(Task) bool
+}
+
+func (q TaskQuery) matches(t Task) bool {
+	if len(q.IDs) > 0 && !containsID(q.IDs, t.ID) {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, s := range q.Statuses {
+			if t.Status == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Match != nil && !q.Match(t) {
+		return false
+	}
+	return true
+}
+
+func allOf(preds []func(Task) bool) func(Task) bool {
+	if len(preds) == 0 {
+		return nil
+	}
+	return func(t Task) bool {
+		for _, p := range preds {
+			if !p(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+type storeOpener func() (Store, error)
+
+var storeBackends = map[string]storeOpener{}
+
+func registerStore(name string, open storeOpener) {
+	storeBackends[name] = open
+}
+
+const defaultStoreBackend = "json"
+
+var storeBackend = defaultStoreBackend
+
+func storeBackendNames() []string {
+	names := make([]string, 0, len(storeBackends))
+	for name := range storeBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func openStore() (Store, error) {
+	name := storeBackend
+	if name == "" {
+		name = defaultStoreBackend
+	}
+	open, ok := storeBackends[name]
+	if !ok {
+		return nil, validationErrorf("almacenamiento desconocido: %s (disponibles: %s)", name, strings.Join(storeBackendNames(), "|"))
+	}
+	return open()
+}
+
+func loadTasks() ([]Task, error) {
+	s, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	return s.Load()
+}
+
+func saveTasks(tasks []Task) error {
+	s, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Save(tasks)
+}
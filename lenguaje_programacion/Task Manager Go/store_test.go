@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestOpenStoreDefault(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", t.TempDir())
+	defer os.Setenv("HOME", originalHome)
+
+	s, err := openStore()
+	if err != nil {
+		t.Fatalf("Error abriendo almacenamiento: %v", err)
+	}
+	defer s.Close()
+
+	if _, ok := s.(*jsonStore); !ok {
+		t.Errorf("Almacenamiento = %T, esperado *jsonStore", s)
+	}
+}
+
+func TestOpenStoreUnknown(t *testing.T) {
+	original := storeBackend
+	storeBackend = "desconocido"
+	defer func() { storeBackend = original }()
+
+	if _, err := openStore(); err == nil {
+		t.Error("Se esperaba error para un almacenamiento desconocido")
+	}
+}
+
+func TestJSONStoreCRUD(t *testing.T) {
+	s := &jsonStore{path: t.TempDir() + "/tasks.json"}
+
+	first, err := s.Insert(NewTask(0, "Primera", ""))
+	if err != nil {
+		t.Fatalf("Error insertando: %v", err)
+	}
+	second, err := s.Insert(NewTask(0, "Segunda", ""))
+	if err != nil {
+		t.Fatalf("Error insertando: %v", err)
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("IDs = %d,%d, esperados 1,2", first.ID, second.ID)
+	}
+
+	second.Status = DONE
+	if err := s.Update(second); err != nil {
+		t.Fatalf("Error actualizando: %v", err)
+	}
+	got, err := s.Get(2)
+	if err != nil {
+		t.Fatalf("Error obteniendo: %v", err)
+	}
+	if got.Status != DONE {
+		t.Errorf("Status = %v, esperado DONE", got.Status)
+	}
+
+	done, err := s.Query(TaskQuery{Statuses: []Status{DONE}})
+	if err != nil {
+		t.Fatalf("Error consultando: %v", err)
+	}
+	if len(done) != 1 || done[0].ID != 2 {
+		t.Errorf("Consulta DONE = %v, esperada solo la tarea 2", done)
+	}
+
+	if err := s.Delete(1); err != nil {
+		t.Fatalf("Error eliminando: %v", err)
+	}
+	if _, err := s.Get(1); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get tras Delete = %v, esperado os.ErrNotExist", err)
+	}
+	if err := s.Update(Task{ID: 99}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Update inexistente = %v, esperado os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func childrenByParent(tasks []Task) map[int][]Task {
+	children := map[int][]Task{}
+	for _, t := range tasks {
+		if t.ParentID != 0 {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		}
+	}
+	return children
+}
+
+// descendantIDs devuelve los IDs de toda la descendencia de id, con los hijos
+// siempre antes que sus padres para poder borrarlos en ese orden.
+func descendantIDs(tasks []Task, id int) []int {
+	children := childrenByParent(tasks)
+	var out []int
+	var walk func(id int)
+	walk = func(id int) {
+		for _, c := range children[id] {
+			walk(c.ID)
+			out = append(out, c.ID)
+		}
+	}
+	walk(id)
+	return out
+}
+
+// subtaskProgress cuenta las subtareas (a cualquier profundidad) de id y
+// cuántas están en DONE.
+func subtaskProgress(tasks []Task, id int) (done, total int) {
+	ids := descendantIDs(tasks, id)
+	byID := map[int]Task{}
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	for _, d := range ids {
+		total++
+		if byID[d].Status == DONE {
+			done++
+		}
+	}
+	return done, total
+}
+
+// validateParent comprueba que parent exista y que asignarlo a id no cree un
+// ciclo (id no puede colgar de sí mismo ni de uno de sus descendientes).
+func validateParent(tasks []Task, id, parent int) error {
+	if parent == 0 {
+		return nil
+	}
+	if parent == id {
+		return fmt.Errorf("una tarea no puede ser su propia subtarea")
+	}
+	parents := map[int]int{}
+	for _, t := range tasks {
+		parents[t.ID] = t.ParentID
+	}
+	if _, ok := parents[parent]; !ok {
+		return fmt.Errorf("la tarea padre %d no existe", parent)
+	}
+	seen := map[int]bool{}
+	for p := parent; p != 0 && !seen[p]; p = parents[p] {
+		if p == id {
+			return fmt.Errorf("la tarea %d es descendiente de %d; se formaría un ciclo", parent, id)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// treeOrder ordena tasks como árbol: cada tarea seguida de sus hijos, conservando
+// el orden relativo de entrada entre hermanos. Las tareas cuyo padre no está
+// en tasks se tratan como raíces.
+func treeOrder(tasks []Task) (ordered []Task, depths []int) {
+	present := map[int]bool{}
+	for _, t := range tasks {
+		present[t.ID] = true
+	}
+	children := map[int][]Task{}
+	var roots []Task
+	for _, t := range tasks {
+		if t.ParentID != 0 && present[t.ParentID] {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+	var walk func(t Task, depth int)
+	walk = func(t Task, depth int) {
+		ordered = append(ordered, t)
+		depths = append(depths, depth)
+		for _, c := range children[t.ID] {
+			walk(c, depth+1)
+		}
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+	return ordered, depths
+}
+
+func progressLabel(done, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d subtareas completadas (%d%%)", done, total, done*100/total)
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+)
+
+func subtaskTestTasks() []Task {
+	return []Task{
+		{ID: 1, Title: "Raíz"},
+		{ID: 2, Title: "Hija", ParentID: 1, Status: DONE},
+		{ID: 3, Title: "Nieta", ParentID: 2},
+		{ID: 4, Title: "Otra hija", ParentID: 1, Status: DONE},
+		{ID: 5, Title: "Suelta"},
+	}
+}
+
+func TestDescendantIDs(t *testing.T) {
+	ids := descendantIDs(subtaskTestTasks(), 1)
+	if len(ids) != 3 {
+		t.Fatalf("Descendientes = %v, esperados 3", ids)
+	}
+	pos := map[int]int{}
+	for i, id := range ids {
+		pos[id] = i
+	}
+	if pos[3] > pos[2] {
+		t.Errorf("Los hijos deben ir antes que sus padres: %v", ids)
+	}
+}
+
+func TestSubtaskProgress(t *testing.T) {
+	done, total := subtaskProgress(subtaskTestTasks(), 1)
+	if done != 2 || total != 3 {
+		t.Errorf("Progreso = %d/%d, esperado 2/3", done, total)
+	}
+	if progressLabel(done, total) != "2/3 subtareas completadas (66%)" {
+		t.Errorf("Etiqueta = %q", progressLabel(done, total))
+	}
+	if progressLabel(subtaskProgress(subtaskTestTasks(), 5)) != "" {
+		t.Error("Una tarea sin subtareas no muestra progreso")
+	}
+}
+
+func TestValidateParent(t *testing.T) {
+	tasks := subtaskTestTasks()
+	if err := validateParent(tasks, 5, 3); err != nil {
+		t.Errorf("Asignar 3 como padre de 5: %v", err)
+	}
+	if err := validateParent(tasks, 1, 3); err == nil {
+		t.Error("Asignar una nieta como padre debe formar un ciclo")
+	}
+	if err := validateParent(tasks, 2, 2); err == nil {
+		t.Error("Una tarea no puede ser su propio padre")
+	}
+	if err := validateParent(tasks, 5, 99); err == nil {
+		t.Error("El padre debe existir")
+	}
+}
+
+func TestTreeOrder(t *testing.T) {
+	tasks := subtaskTestTasks()
+	ordered, depths := treeOrder(tasks)
+	var ids []int
+	for _, t := range ordered {
+		ids = append(ids, t.ID)
+	}
+	expectedIDs := []int{1, 2, 3, 4, 5}
+	expectedDepths := []int{0, 1, 2, 1, 0}
+	for i := range expectedIDs {
+		if ids[i] != expectedIDs[i] || depths[i] != expectedDepths[i] {
+			t.Fatalf("Árbol = %v %v, esperado %v %v", ids, depths, expectedIDs, expectedDepths)
+		}
+	}
+
+	// Sin la raíz, la hija pasa a ser raíz.
+	ordered, depths = treeOrder(tasks[1:3])
+	if ordered[0].ID != 2 || depths[0] != 0 || depths[1] != 1 {
+		t.Errorf("Árbol parcial = %v %v", ordered, depths)
+	}
+}
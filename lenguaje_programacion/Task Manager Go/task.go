@@ -3,6 +3,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -36,26 +38,110 @@ func (s *Status) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &st); err != nil {
 		return err
 	}
+	parsed, err := parseStatus(st)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+func parseStatus(st string) (Status, error) {
 	switch st {
 	case "TODO":
-		*s = TODO
+		return TODO, nil
 	case "IN_PROGRESS":
-		*s = INPROGRESS
+		return INPROGRESS, nil
 	case "DONE":
-		*s = DONE
+		return DONE, nil
 	default:
-		return fmt.Errorf("estado desconocido: %s", st)
+		return TODO, fmt.Errorf("estado desconocido: %s", st)
+	}
+}
+
+type Priority int
+
+const (
+	LOW Priority = iota - 1
+	MEDIUM
+	HIGH
+	URGENT
+)
+
+func (p Priority) String() string {
+	switch p {
+	case LOW:
+		return "LOW"
+	case MEDIUM:
+		return "MEDIUM"
+	case HIGH:
+		return "HIGH"
+	case URGENT:
+		return "URGENT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(b []byte) error {
+	var raw any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*p = MEDIUM
+		return nil
+	}
+	parsed, err := parsePriority(fmt.Sprint(raw))
+	if err != nil {
+		return err
 	}
+	*p = parsed
 	return nil
 }
 
+// parsePriority acepta el nombre (sin distinguir mayúsculas) o un número del 1
+// (LOW) al 4 (URGENT).
+func parsePriority(s string) (Priority, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "LOW", "1":
+		return LOW, nil
+	case "MEDIUM", "2", "":
+		return MEDIUM, nil
+	case "HIGH", "3":
+		return HIGH, nil
+	case "URGENT", "4":
+		return URGENT, nil
+	default:
+		return MEDIUM, fmt.Errorf("prioridad desconocida: %s (usa low|medium|high|urgent o 1-4)", s)
+	}
+}
+
 type Task struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Status      Status    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int         `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Status      Status      `json:"status"`
+	Priority    Priority    `json:"priority,omitempty"`
+	Due         *time.Time  `json:"due,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Project     string      `json:"project,omitempty"`
+	ParentID    int         `json:"parent_id,omitempty"`
+	BlockedBy   []int       `json:"blocked_by,omitempty"`
+	Links       []Link      `json:"links,omitempty"`
+	Worklog     []WorkEntry `json:"worklog,omitempty"`
+	Recurrence  string      `json:"recurrence,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	// StartedAt lo fija setStatus la primera vez que la tarea pasa a
+	// IN_PROGRESS; volver a TODO no lo borra.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// CompletedAt lo fija setStatus al pasar a DONE y lo borra al salir de él.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
 
 func NewTask(id int, title, desc string) Task {
@@ -69,3 +155,120 @@ func NewTask(id int, title, desc string) Task {
 		UpdatedAt:   now,
 	}
 }
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// addTags agrega las etiquetas normalizadas que no estén ya presentes.
+func addTags(tags []string, add ...string) []string {
+	for _, tag := range add {
+		tag = normalizeTag(tag)
+		if tag == "" || containsTag(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func removeTags(tags []string, remove ...string) []string {
+	var out []string
+	for _, tag := range tags {
+		if !containsTag(remove, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func containsTag(tags []string, tag string) bool {
+	tag = normalizeTag(tag)
+	for _, t := range tags {
+		if normalizeTag(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Task) HasAllTags(tags []string) bool {
+	for _, tag := range tags {
+		if !containsTag(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Task) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		if containsTag(t.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProject valida un proyecto con jerarquía separada por puntos, como
+// "backend.api". La cadena vacía o "none" indican que no hay proyecto.
+func parseProject(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "none" {
+		return "", nil
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || strings.ContainsAny(part, " \t") {
+			return "", fmt.Errorf("proyecto inválido: %q (usa nombres separados por puntos, como backend.api)", s)
+		}
+	}
+	return s, nil
+}
+
+// inProject indica si project es parent o uno de sus subproyectos.
+func inProject(project, parent string) bool {
+	return project == parent || strings.HasPrefix(project, parent+".")
+}
+
+// projectAncestors devuelve project y todos sus padres: "a.b.c" -> a, a.b, a.b.c.
+func projectAncestors(project string) []string {
+	if project == "" {
+		return nil
+	}
+	parts := strings.Split(project, ".")
+	out := make([]string, len(parts))
+	for i := range parts {
+		out[i] = strings.Join(parts[:i+1], ".")
+	}
+	return out
+}
+
+const taskSortKeys = "id|priority|due|created|updated"
+
+// sortTasks ordena de forma estable; priority pone primero las más urgentes,
+// due deja al final las tareas sin vencimiento y updated empieza por las
+// modificadas más recientemente.
+func sortTasks(tasks []Task, key string) error {
+	var less func(a, b Task) bool
+	switch key {
+	case "", "id":
+		less = func(a, b Task) bool { return a.ID < b.ID }
+	case "priority":
+		less = func(a, b Task) bool { return a.Priority > b.Priority }
+	case "due":
+		less = func(a, b Task) bool {
+			if a.Due == nil || b.Due == nil {
+				return a.Due != nil
+			}
+			return a.Due.Before(*b.Due)
+		}
+	case "created":
+		less = func(a, b Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "updated":
+		less = func(a, b Task) bool { return a.UpdatedAt.After(b.UpdatedAt) }
+	default:
+		return fmt.Errorf("orden inválido: %s (usa %s)", key, taskSortKeys)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) })
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -140,3 +141,105 @@ func TestTaskJSONSerialization(t *testing.T) {
 		t.Errorf("Status = %v, esperado %v", decoded.Status, task.Status)
 	}
 }
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Priority
+	}{
+		{"low", LOW},
+		{"MEDIUM", MEDIUM},
+		{"High", HIGH},
+		{"urgent", URGENT},
+		{"1", LOW},
+		{"4", URGENT},
+	}
+	for _, tt := range tests {
+		p, err := parsePriority(tt.input)
+		if err != nil || p != tt.expected {
+			t.Errorf("parsePriority(%q) = %v, %v; esperado %v", tt.input, p, err, tt.expected)
+		}
+	}
+	if _, err := parsePriority("altísima"); err == nil {
+		t.Error("Se esperaba error para una prioridad desconocida")
+	}
+}
+
+func TestTaskPriorityJSONBackwardCompatible(t *testing.T) {
+	var old Task
+	if err := json.Unmarshal([]byte(`{"id":1,"title":"Vieja","status":"TODO"}`), &old); err != nil {
+		t.Fatalf("Error al deserializar: %v", err)
+	}
+	if old.Priority != MEDIUM {
+		t.Errorf("Priority = %v, esperado MEDIUM", old.Priority)
+	}
+
+	b, _ := json.Marshal(old)
+	if strings.Contains(string(b), "priority") {
+		t.Errorf("La prioridad por defecto no debería serializarse: %s", b)
+	}
+
+	high := NewTask(2, "Alta", "")
+	high.Priority = HIGH
+	b, _ = json.Marshal(high)
+	var decoded Task
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded.Priority != HIGH {
+		t.Errorf("Ida y vuelta = %v (%v), esperado HIGH", decoded.Priority, err)
+	}
+	if err := json.Unmarshal([]byte(`{"priority":3}`), &decoded); err != nil || decoded.Priority != HIGH {
+		t.Errorf("Prioridad numérica = %v (%v), esperado HIGH", decoded.Priority, err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags := addTags(nil, "Backend", "#api", " backend ", "")
+	if strings.Join(tags, ",") != "backend,api" {
+		t.Errorf("addTags = %v, esperado [backend api]", tags)
+	}
+
+	task := Task{Tags: tags}
+	if !task.HasAllTags([]string{"API", "backend"}) {
+		t.Error("HasAllTags debe ignorar mayúsculas")
+	}
+	if task.HasAllTags([]string{"api", "frontend"}) {
+		t.Error("HasAllTags requiere todas las etiquetas")
+	}
+	if !task.HasAnyTag([]string{"frontend", "api"}) || task.HasAnyTag([]string{"frontend"}) {
+		t.Error("HasAnyTag debe requerir al menos una etiqueta")
+	}
+
+	if left := removeTags(tags, "BACKEND"); len(left) != 1 || left[0] != "api" {
+		t.Errorf("removeTags = %v, esperado [api]", left)
+	}
+	if left := removeTags(tags, "backend", "api"); left != nil {
+		t.Errorf("removeTags = %v, esperado nil", left)
+	}
+}
+
+func TestParseProject(t *testing.T) {
+	for _, valid := range []string{"backend", "backend.api", " web.ui.forms "} {
+		if _, err := parseProject(valid); err != nil {
+			t.Errorf("parseProject(%q): %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"backend..api", ".backend", "mi proyecto"} {
+		if _, err := parseProject(invalid); err == nil {
+			t.Errorf("parseProject(%q) debería fallar", invalid)
+		}
+	}
+	if p, err := parseProject("none"); err != nil || p != "" {
+		t.Errorf("parseProject(none) = %q, %v", p, err)
+	}
+}
+
+func TestProjectHierarchy(t *testing.T) {
+	if !inProject("backend.api", "backend") || !inProject("backend", "backend") {
+		t.Error("backend.api y backend pertenecen a backend")
+	}
+	if inProject("backend2", "backend") || inProject("backend", "backend.api") {
+		t.Error("backend2 no pertenece a backend ni backend a backend.api")
+	}
+	if got := strings.Join(projectAncestors("a.b.c"), ","); got != "a,a.b,a.b.c" {
+		t.Errorf("projectAncestors = %s", got)
+	}
+}
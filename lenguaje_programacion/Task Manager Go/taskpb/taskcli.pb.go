@@ -0,0 +1,1183 @@
+// Servicio gRPC de taskcli: 'taskcli serve --grpc' lo implementa y
+// 'taskcli --remote host:puerto' lo usa como almacenamiento.
+//
+// Para regenerar taskcli.pb.go y taskcli_grpc.pb.go:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative taskpb/taskcli.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: taskpb/taskcli.proto
+
+package taskpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Status int32
+
+const (
+	Status_STATUS_UNSPECIFIED Status = 0
+	Status_STATUS_TODO        Status = 1
+	Status_STATUS_IN_PROGRESS Status = 2
+	Status_STATUS_DONE        Status = 3
+)
+
+// Enum value maps for Status.
+var (
+	Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_TODO",
+		2: "STATUS_IN_PROGRESS",
+		3: "STATUS_DONE",
+	}
+	Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_TODO":        1,
+		"STATUS_IN_PROGRESS": 2,
+		"STATUS_DONE":        3,
+	}
+)
+
+func (x Status) Enum() *Status {
+	p := new(Status)
+	*p = x
+	return p
+}
+
+func (x Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_taskpb_taskcli_proto_enumTypes[0].Descriptor()
+}
+
+func (Status) Type() protoreflect.EnumType {
+	return &file_taskpb_taskcli_proto_enumTypes[0]
+}
+
+func (x Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Status.Descriptor instead.
+func (Status) EnumDescriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{0}
+}
+
+type Priority int32
+
+const (
+	// Sin especificar equivale a MEDIUM, como en tasks.json.
+	Priority_PRIORITY_UNSPECIFIED Priority = 0
+	Priority_PRIORITY_LOW         Priority = 1
+	Priority_PRIORITY_MEDIUM      Priority = 2
+	Priority_PRIORITY_HIGH        Priority = 3
+	Priority_PRIORITY_URGENT      Priority = 4
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "PRIORITY_UNSPECIFIED",
+		1: "PRIORITY_LOW",
+		2: "PRIORITY_MEDIUM",
+		3: "PRIORITY_HIGH",
+		4: "PRIORITY_URGENT",
+	}
+	Priority_value = map[string]int32{
+		"PRIORITY_UNSPECIFIED": 0,
+		"PRIORITY_LOW":         1,
+		"PRIORITY_MEDIUM":      2,
+		"PRIORITY_HIGH":        3,
+		"PRIORITY_URGENT":      4,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_taskpb_taskcli_proto_enumTypes[1].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_taskpb_taskcli_proto_enumTypes[1]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{1}
+}
+
+type TaskEvent_Kind int32
+
+const (
+	TaskEvent_KIND_UNSPECIFIED TaskEvent_Kind = 0
+	TaskEvent_KIND_CREATED     TaskEvent_Kind = 1
+	TaskEvent_KIND_UPDATED     TaskEvent_Kind = 2
+	TaskEvent_KIND_DELETED     TaskEvent_Kind = 3
+)
+
+// Enum value maps for TaskEvent_Kind.
+var (
+	TaskEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_CREATED",
+		2: "KIND_UPDATED",
+		3: "KIND_DELETED",
+	}
+	TaskEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_CREATED":     1,
+		"KIND_UPDATED":     2,
+		"KIND_DELETED":     3,
+	}
+)
+
+func (x TaskEvent_Kind) Enum() *TaskEvent_Kind {
+	p := new(TaskEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x TaskEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_taskpb_taskcli_proto_enumTypes[2].Descriptor()
+}
+
+func (TaskEvent_Kind) Type() protoreflect.EnumType {
+	return &file_taskpb_taskcli_proto_enumTypes[2]
+}
+
+func (x TaskEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskEvent_Kind.Descriptor instead.
+func (TaskEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{11, 0}
+}
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Link) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Link) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type WorkEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Start *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	// Vacío mientras el temporizador está en marcha.
+	End           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	Paused        bool                   `protobuf:"varint,4,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkEntry) Reset() {
+	*x = WorkEntry{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkEntry) ProtoMessage() {}
+
+func (x *WorkEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkEntry.ProtoReflect.Descriptor instead.
+func (*WorkEntry) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WorkEntry) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *WorkEntry) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *WorkEntry) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *WorkEntry) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type Task struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status      Status                 `protobuf:"varint,4,opt,name=status,proto3,enum=taskcli.v1.Status" json:"status,omitempty"`
+	Priority    Priority               `protobuf:"varint,5,opt,name=priority,proto3,enum=taskcli.v1.Priority" json:"priority,omitempty"`
+	Due         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=due,proto3" json:"due,omitempty"`
+	Tags        []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	Project     string                 `protobuf:"bytes,8,opt,name=project,proto3" json:"project,omitempty"`
+	ParentId    int64                  `protobuf:"varint,9,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	BlockedBy   []int64                `protobuf:"varint,10,rep,packed,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	Links       []*Link                `protobuf:"bytes,11,rep,name=links,proto3" json:"links,omitempty"`
+	Worklog     []*WorkEntry           `protobuf:"bytes,12,rep,name=worklog,proto3" json:"worklog,omitempty"`
+	// Regla RRULE, p. ej. "FREQ=WEEKLY;BYDAY=MO".
+	Recurrence string                 `protobuf:"bytes,13,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt  *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Solo salida: versión de la tarea para UpdateTask y DeleteTask, la misma
+	// que el ETag de la API REST.
+	Etag string `protobuf:"bytes,16,opt,name=etag,proto3" json:"etag,omitempty"`
+	// Hora a la que pasó a DONE; la fija el servidor al cambiar status, así que
+	// UpdateTask la ignora.
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	// Hora a la que pasó por primera vez a IN_PROGRESS; como completed_at, la
+	// fija el servidor.
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Task) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Task) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Task) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_STATUS_UNSPECIFIED
+}
+
+func (x *Task) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
+func (x *Task) GetDue() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Due
+	}
+	return nil
+}
+
+func (x *Task) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Task) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Task) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Task) GetBlockedBy() []int64 {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Task) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Task) GetWorklog() []*WorkEntry {
+	if x != nil {
+		return x.Worklog
+	}
+	return nil
+}
+
+func (x *Task) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Task) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Task) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Task) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Task) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+type CreateTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id, created_at, updated_at y etag se ignoran.
+	Task          *Task `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTaskRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Vacío = todos los estados.
+	Statuses []Status `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=taskcli.v1.Status" json:"statuses,omitempty"`
+	// Expresión de filtro con la sintaxis de 'taskcli list --where'.
+	Where string `protobuf:"bytes,2,opt,name=where,proto3" json:"where,omitempty"`
+	// Máximo de tareas por página; 0 = 100, como mucho 1000.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token de la respuesta anterior.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTasksRequest) GetStatuses() []Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetWhere() string {
+	if x != nil {
+		return x.Where
+	}
+	return ""
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ordenadas por id.
+	Tasks []*Task `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	// Vacío en la última página.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// UpdateTask guarda los campos tal cual salvo status: un cambio de estado
+// aplica en el servidor la misma lógica que start y done (temporizador y
+// siguiente repetición).
+type UpdateTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Task  *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	// Campos a modificar con los nombres del mensaje Task: cualquiera salvo id,
+	// created_at, updated_at, started_at, completed_at y etag. Obligatorio:
+	// vacío falla con INVALID_ARGUMENT, igual que status STATUS_UNSPECIFIED.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	// Si no está vacío, la tarea debe tener este etag o la llamada falla con
+	// ABORTED.
+	Etag          string `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRequest) Reset() {
+	*x = UpdateTaskRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateTaskRequest) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *UpdateTaskRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateTaskRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type DeleteTaskRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Eliminar también las subtareas; sin él, una tarea con subtareas da
+	// FAILED_PRECONDITION.
+	Recursive     bool   `protobuf:"varint,2,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	Etag          string `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTaskRequest) Reset() {
+	*x = DeleteTaskRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTaskRequest) ProtoMessage() {}
+
+func (x *DeleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteTaskRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteTaskRequest) GetRecursive() bool {
+	if x != nil {
+		return x.Recursive
+	}
+	return false
+}
+
+func (x *DeleteTaskRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type DeleteTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTaskResponse) Reset() {
+	*x = DeleteTaskResponse{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTaskResponse) ProtoMessage() {}
+
+func (x *DeleteTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTaskResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTaskResponse) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{9}
+}
+
+type WatchTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Vacío = todos los estados. Una tarea que pasa a otro estado fuera del
+	// filtro se notifica como KIND_DELETED.
+	Statuses []Status `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=taskcli.v1.Status" json:"statuses,omitempty"`
+	// Enviar primero un evento CREATED por cada tarea existente.
+	Initial       bool `protobuf:"varint,2,opt,name=initial,proto3" json:"initial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTasksRequest) Reset() {
+	*x = WatchTasksRequest{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTasksRequest) ProtoMessage() {}
+
+func (x *WatchTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTasksRequest.ProtoReflect.Descriptor instead.
+func (*WatchTasksRequest) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchTasksRequest) GetStatuses() []Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *WatchTasksRequest) GetInitial() bool {
+	if x != nil {
+		return x.Initial
+	}
+	return false
+}
+
+type TaskEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  TaskEvent_Kind         `protobuf:"varint,1,opt,name=kind,proto3,enum=taskcli.v1.TaskEvent_Kind" json:"kind,omitempty"`
+	// La tarea tras el cambio; en KIND_DELETED solo lleva el id.
+	Task          *Task `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_taskpb_taskcli_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_taskpb_taskcli_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_taskpb_taskcli_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaskEvent) GetKind() TaskEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return TaskEvent_KIND_UNSPECIFIED
+}
+
+func (x *TaskEvent) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+var File_taskpb_taskcli_proto protoreflect.FileDescriptor
+
+const file_taskpb_taskcli_proto_rawDesc = "" +
+	"\n" +
+	"\x14taskpb/taskcli.proto\x12\n" +
+	"taskcli.v1\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"*\n" +
+	"\x04Link\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"\x97\x01\n" +
+	"\tWorkEntry\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\x12\x16\n" +
+	"\x06paused\x18\x04 \x01(\bR\x06paused\"\xc1\x05\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12*\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x12.taskcli.v1.StatusR\x06status\x120\n" +
+	"\bpriority\x18\x05 \x01(\x0e2\x14.taskcli.v1.PriorityR\bpriority\x12,\n" +
+	"\x03due\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x03due\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12\x18\n" +
+	"\aproject\x18\b \x01(\tR\aproject\x12\x1b\n" +
+	"\tparent_id\x18\t \x01(\x03R\bparentId\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\n" +
+	" \x03(\x03R\tblockedBy\x12&\n" +
+	"\x05links\x18\v \x03(\v2\x10.taskcli.v1.LinkR\x05links\x12/\n" +
+	"\aworklog\x18\f \x03(\v2\x15.taskcli.v1.WorkEntryR\aworklog\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\r \x01(\tR\n" +
+	"recurrence\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04etag\x18\x10 \x01(\tR\x04etag\x12=\n" +
+	"\fcompleted_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x129\n" +
+	"\n" +
+	"started_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\"9\n" +
+	"\x11CreateTaskRequest\x12$\n" +
+	"\x04task\x18\x01 \x01(\v2\x10.taskcli.v1.TaskR\x04task\" \n" +
+	"\x0eGetTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x94\x01\n" +
+	"\x10ListTasksRequest\x12.\n" +
+	"\bstatuses\x18\x01 \x03(\x0e2\x12.taskcli.v1.StatusR\bstatuses\x12\x14\n" +
+	"\x05where\x18\x02 \x01(\tR\x05where\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"c\n" +
+	"\x11ListTasksResponse\x12&\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x10.taskcli.v1.TaskR\x05tasks\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x8a\x01\n" +
+	"\x11UpdateTaskRequest\x12$\n" +
+	"\x04task\x18\x01 \x01(\v2\x10.taskcli.v1.TaskR\x04task\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\"U\n" +
+	"\x11DeleteTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1c\n" +
+	"\trecursive\x18\x02 \x01(\bR\trecursive\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\"\x14\n" +
+	"\x12DeleteTaskResponse\"]\n" +
+	"\x11WatchTasksRequest\x12.\n" +
+	"\bstatuses\x18\x01 \x03(\x0e2\x12.taskcli.v1.StatusR\bstatuses\x12\x18\n" +
+	"\ainitial\x18\x02 \x01(\bR\ainitial\"\xb5\x01\n" +
+	"\tTaskEvent\x12.\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x1a.taskcli.v1.TaskEvent.KindR\x04kind\x12$\n" +
+	"\x04task\x18\x02 \x01(\v2\x10.taskcli.v1.TaskR\x04task\"R\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fKIND_CREATED\x10\x01\x12\x10\n" +
+	"\fKIND_UPDATED\x10\x02\x12\x10\n" +
+	"\fKIND_DELETED\x10\x03*Z\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vSTATUS_TODO\x10\x01\x12\x16\n" +
+	"\x12STATUS_IN_PROGRESS\x10\x02\x12\x0f\n" +
+	"\vSTATUS_DONE\x10\x03*s\n" +
+	"\bPriority\x12\x18\n" +
+	"\x14PRIORITY_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x01\x12\x13\n" +
+	"\x0fPRIORITY_MEDIUM\x10\x02\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x03\x12\x13\n" +
+	"\x0fPRIORITY_URGENT\x10\x042\xa1\x03\n" +
+	"\vTaskService\x12=\n" +
+	"\n" +
+	"CreateTask\x12\x1d.taskcli.v1.CreateTaskRequest\x1a\x10.taskcli.v1.Task\x127\n" +
+	"\aGetTask\x12\x1a.taskcli.v1.GetTaskRequest\x1a\x10.taskcli.v1.Task\x12H\n" +
+	"\tListTasks\x12\x1c.taskcli.v1.ListTasksRequest\x1a\x1d.taskcli.v1.ListTasksResponse\x12=\n" +
+	"\n" +
+	"UpdateTask\x12\x1d.taskcli.v1.UpdateTaskRequest\x1a\x10.taskcli.v1.Task\x12K\n" +
+	"\n" +
+	"DeleteTask\x12\x1d.taskcli.v1.DeleteTaskRequest\x1a\x1e.taskcli.v1.DeleteTaskResponse\x12D\n" +
+	"\n" +
+	"WatchTasks\x12\x1d.taskcli.v1.WatchTasksRequest\x1a\x15.taskcli.v1.TaskEvent0\x01B$Z\"github.com/namoruso/taskcli/taskpbb\x06proto3"
+
+var (
+	file_taskpb_taskcli_proto_rawDescOnce sync.Once
+	file_taskpb_taskcli_proto_rawDescData []byte
+)
+
+func file_taskpb_taskcli_proto_rawDescGZIP() []byte {
+	file_taskpb_taskcli_proto_rawDescOnce.Do(func() {
+		file_taskpb_taskcli_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_taskpb_taskcli_proto_rawDesc), len(file_taskpb_taskcli_proto_rawDesc)))
+	})
+	return file_taskpb_taskcli_proto_rawDescData
+}
+
+var file_taskpb_taskcli_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_taskpb_taskcli_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_taskpb_taskcli_proto_goTypes = []any{
+	(Status)(0),                   // 0: taskcli.v1.Status
+	(Priority)(0),                 // 1: taskcli.v1.Priority
+	(TaskEvent_Kind)(0),           // 2: taskcli.v1.TaskEvent.Kind
+	(*Link)(nil),                  // 3: taskcli.v1.Link
+	(*WorkEntry)(nil),             // 4: taskcli.v1.WorkEntry
+	(*Task)(nil),                  // 5: taskcli.v1.Task
+	(*CreateTaskRequest)(nil),     // 6: taskcli.v1.CreateTaskRequest
+	(*GetTaskRequest)(nil),        // 7: taskcli.v1.GetTaskRequest
+	(*ListTasksRequest)(nil),      // 8: taskcli.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 9: taskcli.v1.ListTasksResponse
+	(*UpdateTaskRequest)(nil),     // 10: taskcli.v1.UpdateTaskRequest
+	(*DeleteTaskRequest)(nil),     // 11: taskcli.v1.DeleteTaskRequest
+	(*DeleteTaskResponse)(nil),    // 12: taskcli.v1.DeleteTaskResponse
+	(*WatchTasksRequest)(nil),     // 13: taskcli.v1.WatchTasksRequest
+	(*TaskEvent)(nil),             // 14: taskcli.v1.TaskEvent
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 16: google.protobuf.FieldMask
+}
+var file_taskpb_taskcli_proto_depIdxs = []int32{
+	15, // 0: taskcli.v1.WorkEntry.start:type_name -> google.protobuf.Timestamp
+	15, // 1: taskcli.v1.WorkEntry.end:type_name -> google.protobuf.Timestamp
+	0,  // 2: taskcli.v1.Task.status:type_name -> taskcli.v1.Status
+	1,  // 3: taskcli.v1.Task.priority:type_name -> taskcli.v1.Priority
+	15, // 4: taskcli.v1.Task.due:type_name -> google.protobuf.Timestamp
+	3,  // 5: taskcli.v1.Task.links:type_name -> taskcli.v1.Link
+	4,  // 6: taskcli.v1.Task.worklog:type_name -> taskcli.v1.WorkEntry
+	15, // 7: taskcli.v1.Task.created_at:type_name -> google.protobuf.Timestamp
+	15, // 8: taskcli.v1.Task.updated_at:type_name -> google.protobuf.Timestamp
+	15, // 9: taskcli.v1.Task.completed_at:type_name -> google.protobuf.Timestamp
+	15, // 10: taskcli.v1.Task.started_at:type_name -> google.protobuf.Timestamp
+	5,  // 11: taskcli.v1.CreateTaskRequest.task:type_name -> taskcli.v1.Task
+	0,  // 12: taskcli.v1.ListTasksRequest.statuses:type_name -> taskcli.v1.Status
+	5,  // 13: taskcli.v1.ListTasksResponse.tasks:type_name -> taskcli.v1.Task
+	5,  // 14: taskcli.v1.UpdateTaskRequest.task:type_name -> taskcli.v1.Task
+	16, // 15: taskcli.v1.UpdateTaskRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 16: taskcli.v1.WatchTasksRequest.statuses:type_name -> taskcli.v1.Status
+	2,  // 17: taskcli.v1.TaskEvent.kind:type_name -> taskcli.v1.TaskEvent.Kind
+	5,  // 18: taskcli.v1.TaskEvent.task:type_name -> taskcli.v1.Task
+	6,  // 19: taskcli.v1.TaskService.CreateTask:input_type -> taskcli.v1.CreateTaskRequest
+	7,  // 20: taskcli.v1.TaskService.GetTask:input_type -> taskcli.v1.GetTaskRequest
+	8,  // 21: taskcli.v1.TaskService.ListTasks:input_type -> taskcli.v1.ListTasksRequest
+	10, // 22: taskcli.v1.TaskService.UpdateTask:input_type -> taskcli.v1.UpdateTaskRequest
+	11, // 23: taskcli.v1.TaskService.DeleteTask:input_type -> taskcli.v1.DeleteTaskRequest
+	13, // 24: taskcli.v1.TaskService.WatchTasks:input_type -> taskcli.v1.WatchTasksRequest
+	5,  // 25: taskcli.v1.TaskService.CreateTask:output_type -> taskcli.v1.Task
+	5,  // 26: taskcli.v1.TaskService.GetTask:output_type -> taskcli.v1.Task
+	9,  // 27: taskcli.v1.TaskService.ListTasks:output_type -> taskcli.v1.ListTasksResponse
+	5,  // 28: taskcli.v1.TaskService.UpdateTask:output_type -> taskcli.v1.Task
+	12, // 29: taskcli.v1.TaskService.DeleteTask:output_type -> taskcli.v1.DeleteTaskResponse
+	14, // 30: taskcli.v1.TaskService.WatchTasks:output_type -> taskcli.v1.TaskEvent
+	25, // [25:31] is the sub-list for method output_type
+	19, // [19:25] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_taskpb_taskcli_proto_init() }
+func file_taskpb_taskcli_proto_init() {
+	if File_taskpb_taskcli_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_taskpb_taskcli_proto_rawDesc), len(file_taskpb_taskcli_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_taskpb_taskcli_proto_goTypes,
+		DependencyIndexes: file_taskpb_taskcli_proto_depIdxs,
+		EnumInfos:         file_taskpb_taskcli_proto_enumTypes,
+		MessageInfos:      file_taskpb_taskcli_proto_msgTypes,
+	}.Build()
+	File_taskpb_taskcli_proto = out.File
+	file_taskpb_taskcli_proto_goTypes = nil
+	file_taskpb_taskcli_proto_depIdxs = nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// uiFilters son las pestañas del panel de lista, en el orden de las teclas 1-4.
+var uiFilters = []struct {
+	label  string
+	status []Status
+}{
+	{"Todas", nil},
+	{"TODO", []Status{TODO}},
+	{"En curso", []Status{INPROGRESS}},
+	{"Hechas", []Status{DONE}},
+}
+
+var statusMarks = map[Status]string{TODO: "[ ]", INPROGRESS: "[~]", DONE: "[x]"}
+
+const uiHelp = "↑/↓ mover · tab/1-4 filtro · a añadir · e editar · s iniciar (S forzar) · d terminar · x eliminar · r recargar · q salir"
+
+type uiMode int
+
+const (
+	uiBrowse uiMode = iota
+	uiAdding
+	uiEditing
+	uiConfirmRemove
+)
+
+var (
+	uiPane     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	uiTab      = lipgloss.NewStyle().Padding(0, 1)
+	uiTabOn    = uiTab.Bold(true).Reverse(true)
+	uiSelected = lipgloss.NewStyle().Bold(true).Reverse(true)
+	uiFaint    = lipgloss.NewStyle().Faint(true)
+	uiErrorMsg = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// uiModel es el estado de 'taskcli ui'. Cada acción abre el almacenamiento
+// con withStore y llama a las mismas funciones que los comandos, así que el
+// bloqueo solo se mantiene mientras dura la operación. Las acciones se
+// ejecutan fuera de Update, en un tea.Cmd, para que la interfaz siga
+// respondiendo mientras esperan el bloqueo o al servidor de --remote.
+type uiModel struct {
+	all    []Task
+	tasks  []Task
+	cursor int
+	filter int
+	mode   uiMode
+	input  textinput.Model
+	msg    string
+	err    bool
+	busy   bool
+	width  int
+	height int
+}
+
+// uiResultMsg es el resultado de una acción de run: su primera línea de
+// salida o su error, y las tareas recargadas después.
+type uiResultMsg struct {
+	all   []Task
+	out   string
+	err   error
+	focus int
+}
+
+func newUIModel() (uiModel, error) {
+	m := uiModel{width: 100, height: 30, input: textinput.New()}
+	m.input.CharLimit = 200
+	all, err := loadUITasks()
+	if err != nil {
+		return m, err
+	}
+	m.setTasks(all, 0)
+	return m, nil
+}
+
+func loadUITasks() ([]Task, error) {
+	var all []Task
+	err := withStore(func(s Store) error {
+		tasks, err := s.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		all = tasks
+		return nil
+	})
+	sortTasks(all, "id")
+	return all, err
+}
+
+// setTasks sustituye la lista y mantiene el cursor sobre la misma tarea, o
+// sobre focus si no es 0, si sigue visible con el filtro activo.
+func (m *uiModel) setTasks(all []Task, focus int) {
+	if t, ok := m.selected(); ok && focus == 0 {
+		focus = t.ID
+	}
+	m.all = all
+	m.applyFilter(focus)
+}
+
+// applyFilter recalcula las tareas visibles sin volver a leer el
+// almacenamiento.
+func (m *uiModel) applyFilter(focus int) {
+	var visible []Task
+	for _, t := range m.all {
+		if statuses := uiFilters[m.filter].status; statuses == nil || statuses[0] == t.Status {
+			visible = append(visible, t)
+		}
+	}
+	m.tasks = visible
+	for i, t := range m.tasks {
+		if t.ID == focus {
+			m.cursor = i
+			return
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.tasks)-1, 0))
+}
+
+func (m uiModel) selected() (Task, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.tasks) {
+		return Task{}, false
+	}
+	return m.tasks[m.cursor], true
+}
+
+// run ejecuta action en un tea.Cmd y recarga las tareas; mientras tanto la
+// barra de estado indica que hay una operación en curso. action puede ser nil
+// para solo recargar.
+func (m *uiModel) run(action func(w io.Writer, s Store) error) tea.Cmd {
+	return m.runFocus(func(w io.Writer, s Store) (int, error) {
+		if action == nil {
+			return 0, nil
+		}
+		return 0, action(w, s)
+	})
+}
+
+// runFocus es run para acciones que eligen la tarea que seleccionar después,
+// p. ej. la recién creada.
+func (m *uiModel) runFocus(action func(w io.Writer, s Store) (int, error)) tea.Cmd {
+	m.busy = true
+	return func() tea.Msg {
+		var out bytes.Buffer
+		var res uiResultMsg
+		res.err = withStore(func(s Store) error {
+			var err error
+			res.focus, err = action(&out, s)
+			return err
+		})
+		res.out, _, _ = strings.Cut(strings.TrimSpace(out.String()), "\n")
+		all, err := loadUITasks()
+		if err != nil {
+			res.err = err
+		} else {
+			res.all = all
+		}
+		return res
+	}
+}
+
+// finish muestra el resultado de run.
+func (m *uiModel) finish(res uiResultMsg) {
+	m.busy = false
+	if res.all != nil {
+		m.setTasks(res.all, res.focus)
+	}
+	if res.err != nil {
+		m.msg, m.err = res.err.Error(), true
+	} else {
+		m.msg, m.err = res.out, false
+	}
+}
+
+// setFilter cambia de pestaña conservando la tarea seleccionada si sigue visible.
+func (m *uiModel) setFilter(i int) {
+	m.filter = (i + len(uiFilters)) % len(uiFilters)
+	t, _ := m.selected()
+	m.applyFilter(t.ID)
+}
+
+func (m uiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case uiResultMsg:
+		m.finish(msg)
+		return m, nil
+	case tea.KeyMsg:
+		switch m.mode {
+		case uiAdding, uiEditing:
+			return m.updateInput(msg)
+		case uiConfirmRemove:
+			m.mode = uiBrowse
+			if t, ok := m.selected(); ok && (msg.String() == "s" || msg.String() == "y") {
+				return m, m.run(func(w io.Writer, s Store) error { return removeTask(w, s, t.ID, true) })
+			}
+			m.msg, m.err = "Eliminación cancelada", false
+			return m, nil
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+func (m uiModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t, ok := m.selected()
+	// Con una operación en curso solo se puede mover el cursor y salir, para
+	// no encadenar cambios sobre una lista que está a punto de cambiar.
+	if m.busy {
+		switch msg.String() {
+		case "r", "a", "e", "s", "S", "d", "x", "delete":
+			return m, nil
+		}
+	}
+	switch key := msg.String(); key {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.tasks)-1, 0))
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = max(len(m.tasks)-1, 0)
+	case "tab", "right", "l":
+		m.setFilter(m.filter + 1)
+	case "shift+tab", "left", "h":
+		m.setFilter(m.filter - 1)
+	case "1", "2", "3", "4":
+		m.setFilter(int(key[0] - '1'))
+	case "r":
+		return m, m.run(nil)
+	case "a":
+		m.mode = uiAdding
+		m.input.Reset()
+		m.input.Placeholder = "Título de la nueva tarea"
+		return m, m.input.Focus()
+	case "e":
+		if ok {
+			m.mode = uiEditing
+			m.input.SetValue(t.Title)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	case "s", "S":
+		if ok {
+			return m, m.run(func(w io.Writer, s Store) error { return startTask(w, s, t.ID, key == "S") })
+		}
+	case "d":
+		if ok {
+			return m, m.run(func(w io.Writer, s Store) error { return setStatus(w, s, t.ID, DONE) })
+		}
+	case "x", "delete":
+		if ok {
+			m.mode = uiConfirmRemove
+		}
+	}
+	return m, nil
+}
+
+func (m uiModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = uiBrowse
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		title := strings.TrimSpace(m.input.Value())
+		if title == "" {
+			m.msg, m.err = "el título no puede estar vacío", true
+			return m, nil
+		}
+		mode := m.mode
+		m.mode = uiBrowse
+		m.input.Blur()
+		if mode == uiAdding {
+			return m, m.runFocus(func(w io.Writer, s Store) (int, error) {
+				t, err := addTask(w, s, NewTask(0, title, ""))
+				return t.ID, err
+			})
+		}
+		if t, ok := m.selected(); ok {
+			return m, m.run(func(w io.Writer, s Store) error {
+				_, err := updateTask(s, t.ID, func(t *Task) error {
+					t.Title = title
+					t.UpdatedAt = timeNow()
+					return nil
+				})
+				if err == nil {
+					fmt.Fprintf(w, "Tarea %d actualizada\n", t.ID)
+				}
+				return err
+			})
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m uiModel) View() string {
+	var b strings.Builder
+	for i, f := range uiFilters {
+		count := 0
+		for _, t := range m.all {
+			if f.status == nil || f.status[0] == t.Status {
+				count++
+			}
+		}
+		style := uiTab
+		if i == m.filter {
+			style = uiTabOn
+		}
+		b.WriteString(style.Render(fmt.Sprintf("%d %s (%d)", i+1, f.label, count)))
+	}
+	b.WriteString("\n")
+
+	// Pestañas, bordes de los paneles, barra de estado y ayuda.
+	rows := max(m.height-5, 3)
+	listWidth := max(m.width*2/5, 24)
+	detailWidth := max(m.width-listWidth-4, 20)
+	list := uiPane.Width(listWidth - 4).Height(rows).MaxWidth(listWidth).Render(m.listView(listWidth-4, rows))
+	detail := uiPane.Width(detailWidth - 4).Height(rows).MaxWidth(detailWidth).Render(m.detailView(rows))
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list, detail))
+	b.WriteString("\n")
+
+	switch {
+	case m.mode == uiAdding:
+		b.WriteString("Nueva tarea: " + m.input.View())
+	case m.mode == uiEditing:
+		t, _ := m.selected()
+		b.WriteString(fmt.Sprintf("Título de la tarea %d: %s", t.ID, m.input.View()))
+	case m.mode == uiConfirmRemove:
+		b.WriteString(m.removePrompt())
+	case m.busy:
+		b.WriteString(uiFaint.Render("Trabajando…"))
+	case m.err:
+		b.WriteString(uiErrorMsg.Render("Error: " + m.msg))
+	default:
+		b.WriteString(m.msg)
+	}
+	b.WriteString("\n" + uiFaint.MaxWidth(m.width).Render(uiHelp))
+	return b.String()
+}
+
+func (m uiModel) listView(width, rows int) string {
+	if len(m.tasks) == 0 {
+		return uiFaint.Render("No hay tareas.")
+	}
+	now := timeNow()
+	first := max(m.cursor-rows+1, 0)
+	var lines []string
+	for i := first; i < len(m.tasks) && i < first+rows; i++ {
+		t := m.tasks[i]
+		mark := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColors[t.Status])).Render(statusMarks[t.Status])
+		line := fmt.Sprintf("[%d] %s%s%s", t.ID, t.Title, priorityLabel(t.Priority), dueLabel(t, now))
+		line = lipgloss.NewStyle().MaxWidth(width - 4).Render(line)
+		if i == m.cursor {
+			line = uiSelected.Render(line)
+		}
+		lines = append(lines, mark+" "+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m uiModel) detailView(rows int) string {
+	t, ok := m.selected()
+	if !ok {
+		return ""
+	}
+	var b bytes.Buffer
+	writeTaskDetail(&b, t, m.all, timeNow())
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	return strings.Join(lines[:min(len(lines), rows)], "\n")
+}
+
+func (m uiModel) removePrompt() string {
+	t, _ := m.selected()
+	if n := len(descendantIDs(m.all, t.ID)); n > 0 {
+		return fmt.Sprintf("¿Eliminar la tarea %d (%s) y sus %d subtareas? (s/n)", t.ID, t.Title, n)
+	}
+	return fmt.Sprintf("¿Eliminar la tarea %d (%s)? (s/n)", t.ID, t.Title)
+}
+
+var cmdUI = &cobra.Command{
+	Use:   "ui",
+	Short: "Abrir la interfaz de terminal a pantalla completa",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+			return validationErrorf("taskcli ui necesita una terminal interactiva")
+		}
+		m, err := newUIModel()
+		if err != nil {
+			return err
+		}
+		_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+		return err
+	},
+}
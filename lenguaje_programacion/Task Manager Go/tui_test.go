@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press envía al modelo las teclas dadas; "enter" y "esc" son teclas
+// especiales y el resto se escribe carácter a carácter. Si una tecla inicia
+// una acción, espera su resultado como haría el programa.
+func press(t *testing.T, m uiModel, keys ...string) uiModel {
+	t.Helper()
+	for _, k := range keys {
+		var msgs []tea.KeyMsg
+		switch k {
+		case "enter":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyEnter})
+		case "esc":
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyEsc})
+		default:
+			for _, r := range k {
+				msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+		}
+		for _, msg := range msgs {
+			next, cmd := m.Update(msg)
+			m = next.(uiModel)
+			if m.busy && cmd != nil {
+				if !strings.Contains(m.View(), "Trabajando…") {
+					t.Errorf("Falta el aviso de operación en curso:\n%s", m.View())
+				}
+				next, _ = m.Update(cmd())
+				m = next.(uiModel)
+			}
+		}
+	}
+	return m
+}
+
+func TestUIActions(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	blocked := NewTask(2, "Desplegar", "")
+	blocked.BlockedBy = []int{1}
+	saveTasks([]Task{NewTask(1, "Preparar", "lista de cambios"), blocked})
+
+	m, err := newUIModel()
+	if err != nil {
+		t.Fatalf("newUIModel: %v", err)
+	}
+	if view := m.View(); !strings.Contains(view, "[1] Preparar") || !strings.Contains(view, "Descripción: lista de cambios") {
+		t.Errorf("Vista inicial sin la lista o el detalle:\n%s", view)
+	}
+
+	m = press(t, m, "j", "s")
+	if !m.err || !strings.Contains(m.msg, "bloqueada por 1") {
+		t.Errorf("start bloqueado = %q, esperado error de dependencias", m.msg)
+	}
+	m = press(t, m, "k", "s")
+	if m.err || m.msg != "Tarea 1 marcada como IN_PROGRESS" {
+		t.Errorf("start = %q", m.msg)
+	}
+
+	// En la pestaña TODO solo queda la tarea 2.
+	m = press(t, m, "2")
+	if len(m.tasks) != 1 || m.tasks[0].ID != 2 {
+		t.Errorf("Filtro TODO = %v, esperada solo la tarea 2", m.tasks)
+	}
+
+	m = press(t, m, "a", "Revisar", "enter")
+	tasks, _ := loadTasks()
+	if len(tasks) != 3 || tasks[2].Title != "Revisar" {
+		t.Fatalf("Tareas tras añadir = %+v", tasks)
+	}
+	if sel, _ := m.selected(); sel.ID != 3 {
+		t.Errorf("Seleccionada = %d, esperada la nueva tarea 3", sel.ID)
+	}
+
+	m = press(t, m, "e", " PR", "enter")
+	if tasks, _ = loadTasks(); tasks[2].Title != "Revisar PR" {
+		t.Errorf("Título = %q, esperado Revisar PR", tasks[2].Title)
+	}
+	m = press(t, m, "e", "esc")
+	if m.mode != uiBrowse {
+		t.Error("esc debería cancelar la edición")
+	}
+
+	m = press(t, m, "d")
+	if tasks, _ = loadTasks(); tasks[2].Status != DONE {
+		t.Errorf("Status = %v, esperado DONE", tasks[2].Status)
+	}
+
+	m = press(t, m, "1", "g", "x", "n")
+	if tasks, _ = loadTasks(); len(tasks) != 3 {
+		t.Errorf("Tareas = %d; responder n no debería eliminar", len(tasks))
+	}
+	m = press(t, m, "x")
+	if !strings.Contains(m.View(), "¿Eliminar la tarea 1 (Preparar)?") {
+		t.Errorf("Falta la confirmación:\n%s", m.View())
+	}
+	press(t, m, "s")
+	if tasks, _ = loadTasks(); len(tasks) != 2 || tasks[0].ID != 2 {
+		t.Errorf("Tareas tras eliminar = %+v", tasks)
+	}
+}
+
+// La acción no toca el almacenamiento hasta que el programa ejecuta su
+// tea.Cmd, y mientras tanto no se aceptan otras.
+func TestUIRunsActionsInCmd(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	saveTasks([]Task{NewTask(1, "Preparar", "")})
+
+	m, err := newUIModel()
+	if err != nil {
+		t.Fatalf("newUIModel: %v", err)
+	}
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = next.(uiModel)
+	if cmd == nil || !m.busy {
+		t.Fatal("d debería devolver un tea.Cmd y marcar la operación en curso")
+	}
+	if tasks, _ := loadTasks(); tasks[0].Status != TODO {
+		t.Error("Update no debería escribir en el almacenamiento")
+	}
+	if _, again := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}); again != nil {
+		t.Error("Con una operación en curso no se debería iniciar otra")
+	}
+
+	next, _ = m.Update(cmd())
+	m = next.(uiModel)
+	if m.busy || m.err || m.msg != "Tarea 1 marcada como DONE" || m.all[0].Status != DONE {
+		t.Errorf("Tras el resultado: busy %t, msg %q, tareas %+v", m.busy, m.msg, m.all)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultWebAddr = "localhost:8081"
+
+//go:embed web
+var webFiles embed.FS
+
+// webSnapshot es la respuesta de /api/snapshot: todas las tareas y el ETag de
+// cada una, para que la página pueda enviar If-Match sin pedirlas una a una.
+type webSnapshot struct {
+	Tasks []Task            `json:"tasks"`
+	ETags map[string]string `json:"etags"`
+}
+
+// newWebHandler sirve la página embebida en / y la API de serve en /api. Como
+// la API, solo acepta cuerpos application/json en cualquier ruta; cmdWeb la
+// protege además con sameOrigin, porque el navegador que abre la página
+// también visita otras webs. Con token, /api lo exige con requireToken; la
+// página no contiene tareas y lo pide al recibir un 401.
+func newWebHandler(token string) http.Handler {
+	static, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", requireToken(http.StripPrefix("/api", newAPIHandler()), token))
+	mux.Handle("GET /api/snapshot", requireToken(http.HandlerFunc(webSnapshotHandler), token))
+	mux.Handle("/", http.FileServerFS(static))
+	return requireJSON(mux)
+}
+
+func webSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	err := withStore(func(s Store) error {
+		tasks, err := s.Load()
+		if err != nil {
+			return storageError("cargando tareas", err)
+		}
+		sortTasks(tasks, "id")
+		snap := webSnapshot{Tasks: tasks, ETags: make(map[string]string, len(tasks))}
+		if snap.Tasks == nil {
+			snap.Tasks = []Task{}
+		}
+		for _, t := range tasks {
+			snap.ETags[strconv.Itoa(t.ID)] = taskETag(t)
+		}
+		writeJSON(w, http.StatusOK, snap)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+var cmdWeb = &cobra.Command{
+	Use:   "web",
+	Short: "Abrir la interfaz web local (lista y tablero)",
+	Long: `Abre la interfaz web local (lista y tablero) sobre la API de serve.
+
+Como serve, sin --token solo escucha en localhost. Con --token la página pide
+el token la primera vez y lo envía en cada llamada a la API.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		serve, err := httpServe(addr, authToken, newWebHandler(authToken))
+		if err != nil {
+			return err
+		}
+		return runServer(addr, "Interfaz web en http://%s", serve)
+	},
+}
+
+func init() {
+	cmdWeb.Flags().String("addr", defaultWebAddr, "Dirección en la que escuchar, p. ej. :8081")
+}
@@ -0,0 +1,263 @@
+"use strict";
+
+// Interfaz web de taskcli: usa la API REST montada en /api y envía el ETag de
+// cada tarea en If-Match para no pisar cambios hechos desde la CLI.
+
+const STATUSES = ["TODO", "IN_PROGRESS", "DONE"];
+const STATUS_LABELS = { TODO: "Pendiente", IN_PROGRESS: "En curso", DONE: "Hecha" };
+const PRIORITIES = ["LOW", "MEDIUM", "HIGH", "URGENT"];
+const PRIORITY_LABELS = { LOW: "Baja", MEDIUM: "Media", HIGH: "Alta", URGENT: "Urgente" };
+
+const state = { tasks: [], etags: {}, view: "list", filter: "" };
+
+const $ = (sel) => document.querySelector(sel);
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs)) {
+    if (k.startsWith("on")) node.addEventListener(k.slice(2), v);
+    else if (v !== undefined && v !== null) node.setAttribute(k, v);
+  }
+  for (const c of children) node.append(c);
+  return node;
+}
+
+function showMessage(text, error = false) {
+  const msg = $("#message");
+  msg.textContent = text;
+  msg.className = error ? "error" : "";
+}
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+// Con taskcli web --token la API responde 401 hasta recibir el token; se pide
+// una vez y se guarda mientras dure la pestaña.
+const TOKEN_KEY = "taskcli-token";
+
+async function api(method, path, body, etag) {
+  const headers = {};
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  if (etag) headers["If-Match"] = etag;
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) headers["Authorization"] = "Bearer " + token;
+  const resp = await fetch("/api" + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (resp.status === 401) {
+    const entered = prompt(token ? "Token no válido. Token de taskcli web:" : "Token de taskcli web:");
+    if (entered) {
+      sessionStorage.setItem(TOKEN_KEY, entered);
+      return api(method, path, body, etag);
+    }
+  }
+  if (!resp.ok) {
+    const data = await resp.json().catch(() => ({}));
+    throw new APIError(resp.status, data.error || resp.statusText);
+  }
+  if (resp.status === 204) return null;
+  const data = await resp.json();
+  const tag = resp.headers.get("ETag");
+  if (tag && data && data.id) state.etags[data.id] = tag;
+  return data;
+}
+
+async function load() {
+  try {
+    const snap = await api("GET", "/snapshot");
+    state.tasks = snap.tasks;
+    state.etags = snap.etags;
+    render();
+  } catch (err) {
+    showMessage("No se pudieron cargar las tareas: " + err.message, true);
+  }
+}
+
+// run ejecuta una modificación; si otro cliente cambió la tarea (412) recarga
+// para que el usuario vea la versión actual antes de repetir el cambio.
+async function run(action, done) {
+  try {
+    await action();
+    if (done) showMessage(done);
+  } catch (err) {
+    if (err.status === 412) {
+      showMessage("La tarea cambió en otro sitio; se ha recargado. Repite el cambio.", true);
+    } else {
+      showMessage(err.message, true);
+    }
+  }
+  await load();
+}
+
+function patch(task, fields) {
+  return run(() => api("PATCH", "/tasks/" + task.id, fields, state.etags[task.id]), "Tarea " + task.id + " actualizada");
+}
+
+// setStatus usa start y done para que el temporizador y las repeticiones se
+// comporten como en la CLI; volver a TODO es un PATCH.
+function setStatus(task, status) {
+  if (status === task.status) return;
+  const etag = state.etags[task.id];
+  return run(async () => {
+    if (status === "IN_PROGRESS") {
+      try {
+        await api("POST", "/tasks/" + task.id + "/start", undefined, etag);
+      } catch (err) {
+        if (err.status !== 409 || !confirm(err.message + "\n\n¿Iniciarla igualmente?")) throw err;
+        await api("POST", "/tasks/" + task.id + "/start?force=true", undefined, etag);
+      }
+    } else if (status === "DONE") {
+      await api("POST", "/tasks/" + task.id + "/done", undefined, etag);
+    } else {
+      await api("PATCH", "/tasks/" + task.id, { status }, etag);
+    }
+  }, "Tarea " + task.id + " marcada como " + status);
+}
+
+function remove(task) {
+  if (!confirm("¿Eliminar la tarea " + task.id + " (" + task.title + ")?")) return;
+  const etag = state.etags[task.id];
+  return run(async () => {
+    try {
+      await api("DELETE", "/tasks/" + task.id, undefined, etag);
+    } catch (err) {
+      if (err.status !== 409 || !confirm(err.message + "\n\n¿Eliminar también las subtareas?")) throw err;
+      await api("DELETE", "/tasks/" + task.id + "?recursive=true", undefined, etag);
+    }
+  }, "Tarea " + task.id + " eliminada");
+}
+
+// dueInput muestra el día del vencimiento en la zona horaria del navegador.
+function dueInput(task) {
+  if (!task.due) return "";
+  const d = new Date(task.due);
+  const pad = (n) => String(n).padStart(2, "0");
+  return d.getFullYear() + "-" + pad(d.getMonth() + 1) + "-" + pad(d.getDate());
+}
+
+// Se envía solo el día (AAAA-MM-DD): la API lo guarda al final del día local,
+// como hace --due.
+function dueValue(date) {
+  return date || null;
+}
+
+function visibleTasks() {
+  const q = state.filter.trim().toLowerCase();
+  if (!q) return state.tasks;
+  return state.tasks.filter((t) =>
+    [t.title, t.project || "", ...(t.tags || [])].some((s) => s.toLowerCase().includes(q)));
+}
+
+// editable es un campo que guarda al salir de él o al pulsar Enter.
+function editable(task, field, value, toPatch, attrs = {}) {
+  const input = el("input", { value, ...attrs });
+  const save = () => {
+    if (input.value !== value) patch(task, { [field]: toPatch(input.value) });
+  };
+  input.addEventListener("change", save);
+  input.addEventListener("keydown", (e) => { if (e.key === "Enter") input.blur(); });
+  return input;
+}
+
+function select(options, labels, value, onchange) {
+  const s = el("select", { onchange: () => onchange(s.value) });
+  for (const o of options) {
+    const opt = el("option", { value: o }, labels[o]);
+    if (o === value) opt.selected = true;
+    s.append(opt);
+  }
+  return s;
+}
+
+function renderList(tasks) {
+  const body = el("tbody");
+  for (const t of tasks) {
+    body.append(el("tr", { class: t.status },
+      el("td", {}, String(t.id)),
+      el("td", { class: "title" }, editable(t, "title", t.title, (v) => v)),
+      el("td", {}, select(STATUSES, STATUS_LABELS, t.status, (v) => setStatus(t, v))),
+      el("td", {}, select(PRIORITIES, PRIORITY_LABELS, t.priority || "MEDIUM", (v) => patch(t, { priority: v }))),
+      el("td", {}, editable(t, "due", dueInput(t), dueValue, { type: "date" })),
+      el("td", {}, editable(t, "project", t.project || "", (v) => v || null)),
+      el("td", {}, editable(t, "tags", (t.tags || []).join(", "),
+        (v) => v.split(",").map((s) => s.trim()).filter(Boolean))),
+      el("td", {}, el("button", { type: "button", title: "Eliminar", onclick: () => remove(t) }, "✕")),
+    ));
+  }
+  const head = el("tr", {}, ...["ID", "Título", "Estado", "Prioridad", "Vence", "Proyecto", "Etiquetas", ""].map((h) => el("th", {}, h)));
+  return el("table", {}, el("thead", {}, head), body);
+}
+
+function renderBoard(tasks) {
+  const board = el("div", { class: "board" });
+  STATUSES.forEach((status, col) => {
+    const cards = tasks.filter((t) => t.status === status);
+    const column = el("section", { class: "column " + status },
+      el("h2", {}, STATUS_LABELS[status] + " (" + cards.length + ")"));
+    column.addEventListener("dragover", (e) => { e.preventDefault(); column.classList.add("over"); });
+    column.addEventListener("dragleave", () => column.classList.remove("over"));
+    column.addEventListener("drop", (e) => {
+      e.preventDefault();
+      const task = state.tasks.find((t) => t.id === Number(e.dataTransfer.getData("text/plain")));
+      if (task) setStatus(task, status);
+    });
+    for (const t of cards) {
+      const card = el("article", { class: "card", draggable: "true" },
+        editable(t, "title", t.title, (v) => v),
+        el("div", { class: "meta" },
+          col > 0 ? el("button", { type: "button", title: "Mover a la izquierda", onclick: () => setStatus(t, STATUSES[col - 1]) }, "←") : "",
+          el("span", {}, "#" + t.id + " · " + PRIORITY_LABELS[t.priority || "MEDIUM"] + (t.due ? " · " + dueInput(t) : "")),
+          col < STATUSES.length - 1 ? el("button", { type: "button", title: "Mover a la derecha", onclick: () => setStatus(t, STATUSES[col + 1]) }, "→") : "",
+        ));
+      card.addEventListener("dragstart", (e) => e.dataTransfer.setData("text/plain", String(t.id)));
+      column.append(card);
+    }
+    board.append(column);
+  });
+  return board;
+}
+
+function render() {
+  const tasks = visibleTasks();
+  const view = $("#view");
+  view.replaceChildren(state.view === "board" ? renderBoard(tasks) : renderList(tasks));
+  if (state.tasks.length === 0) view.append(el("p", {}, "No hay tareas."));
+}
+
+$("#new-task").addEventListener("submit", (e) => {
+  e.preventDefault();
+  const form = e.target;
+  const f = form.elements;
+  const body = { title: f.title.value, priority: f.priority.value };
+  if (f.due.value) body.due = dueValue(f.due.value);
+  if (f.project.value) body.project = f.project.value;
+  run(async () => {
+    const t = await api("POST", "/tasks", body);
+    form.reset();
+    showMessage("Tarea creada: ID=" + t.id);
+  });
+});
+
+for (const button of document.querySelectorAll("nav button")) {
+  button.addEventListener("click", () => {
+    state.view = button.dataset.view;
+    document.querySelectorAll("nav button").forEach((b) => b.classList.toggle("active", b === button));
+    render();
+  });
+}
+
+$("#filter").addEventListener("input", (e) => {
+  state.filter = e.target.value;
+  render();
+});
+
+// Volver a la pestaña recarga los cambios hechos desde la CLI.
+window.addEventListener("focus", load);
+
+load();
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebHandler(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newWebHandler("")
+
+	rec := apiRequest(t, h, "GET", "/", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<script src="app.js">`) {
+		t.Fatalf("GET / = %d %s", rec.Code, rec.Body)
+	}
+	if rec = apiRequest(t, h, "GET", "/app.js", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Type"), "javascript") {
+		t.Errorf("GET /app.js = %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	// La API de serve está montada en /api.
+	rec = apiRequest(t, h, "POST", "/api/tasks", `{"title":"Desde la web"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/tasks = %d %s", rec.Code, rec.Body)
+	}
+	etag := rec.Header().Get("ETag")
+
+	rec = apiRequest(t, h, "GET", "/api/snapshot", "")
+	var snap webSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("snapshot: %v: %s", err, rec.Body)
+	}
+	if len(snap.Tasks) != 1 || snap.Tasks[0].Title != "Desde la web" || snap.ETags["1"] != etag {
+		t.Errorf("Snapshot = %+v, esperado ETag %s", snap, etag)
+	}
+
+	if rec = apiRequest(t, h, "POST", "/api/tasks/1/done", "", "If-Match", etag); rec.Code != http.StatusOK {
+		t.Errorf("POST /api/tasks/1/done = %d %s", rec.Code, rec.Body)
+	}
+	if tasks, _ := loadTasks(); tasks[0].Status != DONE {
+		t.Errorf("Status = %v, esperado DONE", tasks[0].Status)
+	}
+}
+
+func TestWebHandlerToken(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	h := newWebHandler("secreto")
+
+	// La página se sirve sin token; es ella quien lo pide.
+	if rec := apiRequest(t, h, "GET", "/", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET / = %d, esperado 200", rec.Code)
+	}
+	for _, path := range []string{"/api/snapshot", "/api/tasks"} {
+		if rec := apiRequest(t, h, "GET", path, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s sin token = %d, esperado 401", path, rec.Code)
+		}
+		if rec := apiRequest(t, h, "GET", path, "", "Authorization", "Bearer secreto"); rec.Code != http.StatusOK {
+			t.Errorf("GET %s con token = %d %s", path, rec.Code, rec.Body)
+		}
+	}
+	if rec := apiRequest(t, h, "POST", "/api/tasks", `{"title":"x"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/tasks sin token = %d, esperado 401", rec.Code)
+	}
+	if _, err := httpServe(":8081", "", h); err == nil {
+		t.Error("web sin token no debería escuchar fuera de localhost")
+	}
+}
+
+func TestWebHandlerRejectsCrossSite(t *testing.T) {
+	cleanup := setupTestEnv(t)
+	defer cleanup()
+	ln := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8081}
+	h := sameOrigin(newWebHandler(""), defaultWebAddr, ln)
+	request := func(method, path, host, origin, contentType, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Host = host
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Lo que envía la propia página.
+	if code := request("POST", "/api/tasks", "localhost:8081", "http://localhost:8081", "application/json", `{"title":"Propia"}`); code != http.StatusCreated {
+		t.Fatalf("POST desde la página = %d, esperado 201", code)
+	}
+	if code := request("GET", "/api/snapshot", "localhost:8081", "", "", ""); code != http.StatusOK {
+		t.Errorf("GET /api/snapshot = %d, esperado 200", code)
+	}
+
+	tests := []struct {
+		name, method, path, host, origin, contentType, body string
+		want                                                int
+	}{
+		{"formulario de otra web", "POST", "/api/tasks", "localhost:8081", "http://evil.example", "text/plain", `{"title":"x"}`, http.StatusForbidden},
+		{"texto sin Origin", "POST", "/api/tasks", "localhost:8081", "", "text/plain", `{"title":"x"}`, http.StatusUnsupportedMediaType},
+		{"formulario sin Origin", "POST", "/api/tasks/1/done", "localhost:8081", "", "application/x-www-form-urlencoded", "a=1", http.StatusUnsupportedMediaType},
+		{"DNS rebinding", "GET", "/api/snapshot", "evil.example:8081", "", "", "", http.StatusForbidden},
+		{"DNS rebinding en POST", "POST", "/api/tasks/1/done", "evil.example:8081", "http://evil.example:8081", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if code := request(tt.method, tt.path, tt.host, tt.origin, tt.contentType, tt.body); code != tt.want {
+			t.Errorf("%s: %s %s = %d, esperado %d", tt.name, tt.method, tt.path, code, tt.want)
+		}
+	}
+	if tasks, _ := loadTasks(); len(tasks) != 1 || tasks[0].Status != TODO {
+		t.Errorf("Tareas = %+v, esperada solo la propia en TODO", tasks)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkEntry es un intervalo de trabajo sobre una tarea. End es nil mientras el
+// temporizador está en marcha; Paused indica que el intervalo terminó con
+// pause y se puede continuar con resume.
+type WorkEntry struct {
+	Start  time.Time  `json:"start"`
+	End    *time.Time `json:"end,omitempty"`
+	Note   string     `json:"note,omitempty"`
+	Paused bool       `json:"paused,omitempty"`
+}
+
+func (e WorkEntry) duration(now time.Time) time.Duration {
+	if e.End == nil {
+		return now.Sub(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+var (
+	errTimerRunning    = errors.New("el temporizador ya está en marcha")
+	errTimerNotRunning = errors.New("el temporizador no está en marcha")
+	errTimerNotPaused  = errors.New("el temporizador no está en pausa")
+)
+
+// runningEntry devuelve el índice del intervalo abierto o -1.
+func (t Task) runningEntry() int {
+	for i, e := range t.Worklog {
+		if e.End == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t Task) timerRunning() bool {
+	return t.runningEntry() >= 0
+}
+
+// timerPaused busca el intervalo que terminó con pause en lugar de mirar el
+// último: los que agrega log después no interrumpen la pausa. Solo la sesión
+// en pausa conserva Paused, porque start, resume y stop lo borran.
+func (t Task) timerPaused() bool {
+	if t.timerRunning() {
+		return false
+	}
+	for _, e := range t.Worklog {
+		if e.Paused {
+			return true
+		}
+	}
+	return false
+}
+
+// endPause da por terminada la sesión en pausa, si la hay.
+func (t *Task) endPause() {
+	for i := range t.Worklog {
+		t.Worklog[i].Paused = false
+	}
+}
+
+func (t *Task) startTimer(now time.Time) error {
+	if t.timerRunning() {
+		return errTimerRunning
+	}
+	t.endPause()
+	t.Worklog = append(t.Worklog, WorkEntry{Start: now})
+	return nil
+}
+
+func (t *Task) closeTimer(now time.Time, paused bool) error {
+	i := t.runningEntry()
+	if i < 0 {
+		return errTimerNotRunning
+	}
+	t.Worklog[i].End = &now
+	t.Worklog[i].Paused = paused
+	return nil
+}
+
+func (t *Task) pauseTimer(now time.Time) error {
+	return t.closeTimer(now, true)
+}
+
+func (t *Task) resumeTimer(now time.Time) error {
+	if !t.timerPaused() {
+		return errTimerNotPaused
+	}
+	return t.startTimer(now)
+}
+
+// stopTimer cierra el intervalo en marcha o, si estaba en pausa, da la sesión
+// por terminada para que resume ya no la continúe.
+func (t *Task) stopTimer(now time.Time) error {
+	if t.timerPaused() {
+		t.endPause()
+		return nil
+	}
+	return t.closeTimer(now, false)
+}
+
+// logWork agrega un intervalo manual de duración d que termina en end.
+func (t *Task) logWork(end time.Time, d time.Duration, note string) {
+	t.Worklog = append(t.Worklog, WorkEntry{Start: end.Add(-d), End: &end, Note: note})
+}
+
+func (t Task) trackedTime(now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range t.Worklog {
+		total += e.duration(now)
+	}
+	return total
+}
+
+// parseWorkDuration acepta duraciones de Go ("1h30m", "45m", "1.5h") y
+// minutos sin unidad ("90").
+func parseWorkDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if _, err := strconv.Atoi(s); err == nil {
+		s += "m"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("duración inválida: %s (usa 1h30m, 45m o 1.5h)", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("la duración debe ser positiva: %s", s)
+	}
+	return d, nil
+}
+
+// formatWorked redondea al minuto: "2h05m", "45m".
+func formatWorked(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h > 0 {
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
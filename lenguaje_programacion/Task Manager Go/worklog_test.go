@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerLifecycle(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return base.Add(time.Duration(min) * time.Minute) }
+	task := NewTask(1, "Facturable", "")
+
+	if err := task.pauseTimer(at(0)); err != errTimerNotRunning {
+		t.Errorf("pause sin temporizador = %v, esperado errTimerNotRunning", err)
+	}
+	if err := task.startTimer(at(0)); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := task.startTimer(at(5)); err != errTimerRunning {
+		t.Errorf("start repetido = %v, esperado errTimerRunning", err)
+	}
+	if got := task.trackedTime(at(20)); got != 20*time.Minute {
+		t.Errorf("Tiempo en marcha = %v, esperado 20m", got)
+	}
+	if err := task.pauseTimer(at(30)); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if !task.timerPaused() || task.timerRunning() {
+		t.Error("El temporizador debería estar en pausa")
+	}
+	if err := task.resumeTimer(at(60)); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err := task.stopTimer(at(75)); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := task.resumeTimer(at(80)); err != errTimerNotPaused {
+		t.Errorf("resume tras stop = %v, esperado errTimerNotPaused", err)
+	}
+	if got := task.trackedTime(at(500)); got != 45*time.Minute {
+		t.Errorf("Tiempo total = %v, esperado 45m", got)
+	}
+
+	task.logWork(at(120), 90*time.Minute, "code review")
+	last := task.Worklog[len(task.Worklog)-1]
+	if !last.Start.Equal(at(30)) || last.Note != "code review" {
+		t.Errorf("Entrada manual = %+v", last)
+	}
+	if got := formatWorked(task.trackedTime(at(500))); got != "2h15m" {
+		t.Errorf("Total = %s, esperado 2h15m", got)
+	}
+}
+
+func TestStopPausedTimer(t *testing.T) {
+	now := time.Now()
+	task := NewTask(1, "Tarea", "")
+	task.startTimer(now)
+	task.pauseTimer(now.Add(time.Minute))
+	if err := task.stopTimer(now.Add(time.Hour)); err != nil {
+		t.Fatalf("stop en pausa: %v", err)
+	}
+	if task.timerPaused() || task.trackedTime(now.Add(2*time.Hour)) != time.Minute {
+		t.Errorf("stop en pausa no debería sumar tiempo: %+v", task.Worklog)
+	}
+}
+
+func TestParseWorkDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"1h30m": 90 * time.Minute,
+		"45m":   45 * time.Minute,
+		"1.5h":  90 * time.Minute,
+		"90":    90 * time.Minute,
+	}
+	for in, want := range tests {
+		if got, err := parseWorkDuration(in); err != nil || got != want {
+			t.Errorf("parseWorkDuration(%q) = %v, %v; esperado %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "hora", "-1h", "0m"} {
+		if _, err := parseWorkDuration(in); err == nil {
+			t.Errorf("parseWorkDuration(%q) debería fallar", in)
+		}
+	}
+}
+
+func TestFormatWorked(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                               "0m",
+		44*time.Minute + 40*time.Second: "45m",
+		2*time.Hour + 5*time.Minute:     "2h05m",
+		26 * time.Hour:                  "26h00m",
+	}
+	for d, want := range tests {
+		if got := formatWorked(d); got != want {
+			t.Errorf("formatWorked(%v) = %s, esperado %s", d, got, want)
+		}
+	}
+}
+
+// Un intervalo manual agregado con log durante una pausa no la interrumpe.
+func TestPausedTimerWithManualEntry(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(min int) time.Time { return base.Add(time.Duration(min) * time.Minute) }
+	task := NewTask(1, "Facturable", "")
+
+	task.startTimer(at(0))
+	task.pauseTimer(at(30))
+	task.logWork(at(60), 15*time.Minute, "llamada")
+	if !task.timerPaused() {
+		t.Fatalf("Tras log el temporizador debería seguir en pausa: %+v", task.Worklog)
+	}
+	if label := timerLabel(task); label != " (temporizador en pausa)" {
+		t.Errorf("Etiqueta = %q", label)
+	}
+	if err := task.resumeTimer(at(90)); err != nil {
+		t.Fatalf("resume tras log: %v", err)
+	}
+	if task.timerPaused() || !task.timerRunning() {
+		t.Errorf("Tras resume el temporizador debería estar en marcha: %+v", task.Worklog)
+	}
+	if err := task.stopTimer(at(100)); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if got := task.trackedTime(at(500)); got != 55*time.Minute {
+		t.Errorf("Tiempo total = %v, esperado 55m", got)
+	}
+
+	// stop durante la pausa también la termina aunque haya intervalos manuales.
+	task.startTimer(at(200))
+	task.pauseTimer(at(210))
+	task.logWork(at(230), 5*time.Minute, "")
+	if err := task.stopTimer(at(240)); err != nil || task.timerPaused() {
+		t.Errorf("stop en pausa tras log = %v, en pausa = %v", err, task.timerPaused())
+	}
+	if err := task.resumeTimer(at(250)); err != errTimerNotPaused {
+		t.Errorf("resume tras stop = %v, esperado errTimerNotPaused", err)
+	}
+}